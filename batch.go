@@ -0,0 +1,75 @@
+package log
+
+import "context"
+
+// BatchEntry is a single message of a LogBatch call
+type BatchEntry struct {
+	Level  Level
+	Format string
+	Args   []interface{}
+}
+
+// batchLoggersContextKey is the context key holding the loggers a batch is
+// sent to, taken once for the whole batch
+const batchLoggersContextKey contextKey = "batch_loggers"
+
+// LogBatch logs every entry in order, each one gated by the log level like the
+// matching Error, Warn, Info, Debug or Trace call, entries with any other level
+// are skipped. The loggers and the correlation id are taken once for the whole
+// batch and file loggers check their rotation once when it starts, so a file
+// can grow past its maximum size by the size of one batch. Filters, counts,
+// dedup and sampling still apply to each entry, for bulk ingestion such as
+// replaying stored events.
+//
+// Example:
+//
+//	service := log.New()
+//	service.LogBatch([]log.BatchEntry{
+//	    {Level: log.Info, Format: "order %s created", Args: []interface{}{"A-1"}},
+//	    {Level: log.Warning, Format: "order %s delayed", Args: []interface{}{"A-1"}},
+//	})
+//	// Output: order A-1 created
+//	// Output: order A-1 delayed
+func (l *LoggerService) LogBatch(entries []BatchEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	loggers := l.getLoggers()
+	for _, logger := range loggers {
+		if bl, ok := logger.(batchLogger); ok {
+			bl.beginBatch()
+			defer bl.endBatch()
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), batchLoggersContextKey, loggers)
+	if correlationId := l.resolveCorrelationId(ctx); correlationId != "" {
+		ctx = ContextWithCorrelationId(ctx, correlationId)
+	}
+	for _, entry := range entries {
+		switch entry.Level {
+		case Error:
+			l.ErrorCtx(ctx, entry.Format, entry.Args...)
+		case Warning:
+			l.WarnCtx(ctx, entry.Format, entry.Args...)
+		case Info:
+			l.InfoCtx(ctx, entry.Format, entry.Args...)
+		case Debug:
+			l.DebugCtx(ctx, entry.Format, entry.Args...)
+		case Trace:
+			l.TraceCtx(ctx, entry.Format, entry.Args...)
+		}
+	}
+}
+
+// loggersFor returns the loggers a message is sent to, the batch loggers
+// stored in the context by LogBatch or the registered loggers
+func (l *LoggerService) loggersFor(ctx context.Context) []Logger {
+	if ctx != nil {
+		if loggers, ok := ctx.Value(batchLoggersContextKey).([]Logger); ok {
+			return loggers
+		}
+	}
+	return l.getLoggers()
+}
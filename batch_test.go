@@ -0,0 +1,91 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerService_LogBatch(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+
+	service.LogBatch([]BatchEntry{
+		{Level: Info, Format: "order %s created", Args: []interface{}{"A-1"}},
+		{Level: Debug, Format: "hidden at info"},
+		{Level: Warning, Format: "order %s delayed", Args: []interface{}{"A-1"}},
+		{Level: Error, Format: "order %s failed", Args: []interface{}{"A-2"}},
+	})
+
+	levels := make([]string, 0)
+	messages := make([]string, 0)
+	for _, msg := range mockLogger.PrintedMessages {
+		levels = append(levels, msg.Level)
+		messages = append(messages, msg.Message)
+	}
+	assert.Equal(t, []string{"info", "warn", "error"}, levels)
+	assert.Equal(t, []string{"order A-1 created", "order A-1 delayed", "order A-2 failed"}, messages)
+	assert.Equal(t, int64(1), service.Stats()["warn"])
+}
+
+func TestLoggerService_LogBatchUsesOneLoggerSnapshot(t *testing.T) {
+	first := &MockLogger{}
+	service := &LoggerService{LogLevel: Info, Loggers: []Logger{first}}
+	service.AddFilter(func(level Level, message string) bool {
+		// A logger added during the batch does not receive the rest of it
+		if message == "first" {
+			service.AddLogger(&MockLogger{})
+		}
+		return true
+	})
+
+	service.LogBatch([]BatchEntry{{Level: Info, Format: "first"}, {Level: Info, Format: "second"}})
+
+	assert.Len(t, first.PrintedMessages, 2)
+	assert.Empty(t, service.Loggers[1].(*MockLogger).PrintedMessages)
+}
+
+func TestLoggerService_LogBatchSkipsUnknownLevels(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{LogLevel: Trace, Loggers: []Logger{mockLogger}}
+
+	service.LogBatch([]BatchEntry{
+		{Level: Level(-1), Format: "below error"},
+		{Level: Info, Format: "kept"},
+		{Level: Level(10), Format: "above trace"},
+	})
+
+	assert.Equal(t, []string{"kept"}, mockMessages(mockLogger))
+}
+
+func TestLoggerService_LogBatchRotatesOnce(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "batch.log")
+	fileLogger := FileLogger{filename: logFile, options: []FileOption{WithMaxSize(100)}}.Init().(*FileLogger)
+	defer fileLogger.Close()
+	service := &LoggerService{LogLevel: Info, Loggers: []Logger{fileLogger}}
+
+	entries := make([]BatchEntry, 0)
+	for i := 0; i < 5; i++ {
+		entries = append(entries, BatchEntry{Level: Info, Format: fmt.Sprintf("a message long enough to pass the maximum size %d", i)})
+	}
+	service.LogBatch(entries)
+
+	_, err := os.Stat(logFile + ".01")
+	assert.True(t, os.IsNotExist(err), "Expected no rotation inside the batch")
+	content, err := os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, strings.Count(string(content), "a message long enough"))
+
+	// The rotation is checked again once the batch is over
+	service.Info("after the batch")
+	rotated, err := os.ReadFile(logFile + ".01")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, strings.Count(string(rotated), "a message long enough"))
+}
@@ -1,35 +1,114 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	strcolor "github.com/cjlapao/common-go/strcolor"
 	"github.com/google/uuid"
 )
 
+// LogMessageSchemaVersion is the current version of the LogMessage schema,
+// used when the schema version is enabled without an explicit value
+const LogMessageSchemaVersion = "1"
+
+// drainPollInterval is how often Drain checks the subscriber buffers
+const drainPollInterval = 5 * time.Millisecond
+
 type LogMessage struct {
-	Level     string
-	Message   string
-	Timestamp time.Time
-	Icon      LoggerIcon
-	IsTask    bool
+	Level         string         `json:"level"`
+	Message       string         `json:"message"`
+	Timestamp     time.Time      `json:"timestamp"`
+	Icon          LoggerIcon     `json:"icon"`
+	IsTask        bool           `json:"is_task"`
+	IsComplete    bool           `json:"is_complete,omitempty"`
+	SchemaVersion string         `json:"schema_version,omitempty"`
+	Error         string         `json:"error,omitempty"`
+	Fields        map[string]any `json:"fields,omitempty"`
+	Source        string         `json:"logger,omitempty"`
+	Sampled       bool           `json:"sampled,omitempty"`
+	Repeat        int            `json:"repeat,omitempty"`
 }
 
+// defaultSubscriberBuffer is the channel buffer size of a subscription when
+// none is given
+const defaultSubscriberBuffer = 100
+
 type Subscriber struct {
-	id      string
-	filter  func(LogMessage) bool
-	channel chan LogMessage
+	id        string
+	filter    func(LogMessage) bool
+	channel   chan LogMessage
+	blocking  bool
+	dropped   *atomic.Uint64
+	closing   chan struct{}
+	closeOnce *sync.Once
+}
+
+// markClosing releases a blocking send waiting on the subscription, so it
+// can be closed without waiting for its reader
+func (s Subscriber) markClosing() {
+	s.closeOnce.Do(func() { close(s.closing) })
+}
+
+// logMessageJSON is the wire format of a LogMessage, the field names and
+// their order are the schema downstream consumers parse
+type logMessageJSON struct {
+	Level         string         `json:"level"`
+	Message       string         `json:"message"`
+	Timestamp     string         `json:"timestamp,omitempty"`
+	Icon          LoggerIcon     `json:"icon"`
+	IsTask        bool           `json:"is_task"`
+	IsComplete    bool           `json:"is_complete,omitempty"`
+	SchemaVersion string         `json:"schema_version,omitempty"`
+	Error         string         `json:"error,omitempty"`
+	Fields        map[string]any `json:"fields,omitempty"`
+	Source        string         `json:"logger,omitempty"`
+	Sampled       bool           `json:"sampled,omitempty"`
+	Repeat        int            `json:"repeat,omitempty"`
+}
+
+// wire returns the message in its wire format
+func (m LogMessage) wire() logMessageJSON {
+	return logMessageJSON{
+		Level:         m.Level,
+		Message:       m.Message,
+		Timestamp:     m.Timestamp.Format(time.RFC3339Nano),
+		Icon:          m.Icon,
+		IsTask:        m.IsTask,
+		IsComplete:    m.IsComplete,
+		SchemaVersion: m.SchemaVersion,
+		Error:         m.Error,
+		Fields:        m.Fields,
+		Source:        m.Source,
+		Sampled:       m.Sampled,
+		Repeat:        m.Repeat,
+	}
 }
 
-// String returns a formatted string representation of the LogMessage
+// MarshalJSON renders the message with a stable schema, level, message,
+// timestamp as RFC 3339 with fractional seconds, icon and is_task always
+// come first, the optional fields follow only when they are set
+func (m LogMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.wire())
+}
+
+// String returns a formatted string representation of the LogMessage, the
+// fields are appended as key=value pairs sorted by key
 func (m LogMessage) String() string {
 	timestamp := m.Timestamp.Format(time.RFC3339)
+	text := fmt.Sprintf("[%s] %s: %s", timestamp, m.Level, m.Message)
 	if m.Icon != "" {
-		return fmt.Sprintf("[%s] %s %s: %s", timestamp, m.Icon, m.Level, m.Message)
+		text = fmt.Sprintf("[%s] %s %s: %s", timestamp, m.Icon, m.Level, m.Message)
 	}
-	return fmt.Sprintf("[%s] %s: %s", timestamp, m.Level, m.Message)
+
+	for _, key := range sortedKeys(m.Fields) {
+		text += " " + key + "=" + fieldValue(m.Fields[key])
+	}
+	return text
 }
 
 // ChannelLogger Command Line Logger implementation
@@ -37,8 +116,16 @@ type ChannelLogger struct {
 	useTimestamp      bool
 	userCorrelationId bool
 	useIcons          bool
+	correlationEnv    string
+	iconSeparator     string
+	schemaVersion     string
+	bufferSize        int
+	redactors         []Redactor
 	subscribers       []Subscriber
+	closers           map[string]Subscriber
+	draining          atomic.Bool
 	channelMutex      sync.RWMutex
+	closingMutex      sync.Mutex
 }
 
 func (l *ChannelLogger) Init() Logger {
@@ -46,7 +133,9 @@ func (l *ChannelLogger) Init() Logger {
 		useTimestamp:      false,
 		userCorrelationId: false,
 		useIcons:          false,
+		bufferSize:        l.bufferSize,
 		subscribers:       make([]Subscriber, 0),
+		closers:           make(map[string]Subscriber),
 		channelMutex:      sync.RWMutex{},
 	}
 }
@@ -67,42 +156,162 @@ func (l *ChannelLogger) UseIcons(value bool) {
 	l.useIcons = value
 }
 
+// SetCorrelationEnv sets the environment variable the correlation id is read
+// from, CORRELATION_ID when empty
+func (l *ChannelLogger) SetCorrelationEnv(name string) {
+	l.correlationEnv = name
+}
+
+// SetIconSeparator sets the text between the icon and the message, an empty
+// separator restores the single space
+func (l *ChannelLogger) SetIconSeparator(separator string) {
+	l.iconSeparator = separator
+}
+
+// SetRedactors sets the redactors run on every message before it is written
+func (l *ChannelLogger) SetRedactors(redactors []Redactor) {
+	l.redactors = redactors
+}
+
+// SetSchemaVersion stamps the version into every emitted LogMessage, an empty
+// version leaves the field out
+func (l *ChannelLogger) SetSchemaVersion(version string) {
+	l.schemaVersion = version
+}
+
+// SetBufferSize sets the channel buffer size of the subscriptions created
+// with Subscribe afterwards, a size below one uses the default of 100
+func (l *ChannelLogger) SetBufferSize(size int) {
+	l.channelMutex.Lock()
+	defer l.channelMutex.Unlock()
+
+	l.bufferSize = size
+}
+
+// WouldLog reports whether a message would reach a subscriber, the level is
+// gated by the service before the message reaches the logger
+func (l *ChannelLogger) WouldLog(level Level) bool {
+	l.channelMutex.RLock()
+	defer l.channelMutex.RUnlock()
+
+	return len(l.subscribers) > 0
+}
+
 func (l *ChannelLogger) printMessage(format string, icon LoggerIcon, level string, words ...interface{}) {
-	if len(l.subscribers) == 0 {
-		return // Do nothing if no subscribers
-	}
+	l.printStructured("", messageMeta{}, format, icon, level, words...)
+}
 
-	if len(words) > 0 {
-		format = fmt.Sprintf(format, words...)
-	}
+// printStructured sends a message carrying the source and fields to the
+// subscribers, channel messages do not carry the correlation id
+func (l *ChannelLogger) printStructured(correlationId string, meta messageMeta, format string, icon LoggerIcon, level string, words ...interface{}) {
+	msg := newLogMessage(format, icon, level, l.useIcons, l.iconSeparator, words...)
+	meta.apply(&msg)
+	l.publish(msg)
+}
 
-	msg := LogMessage{
-		Level:     level,
-		Message:   format,
-		Timestamp: time.Now(),
-		Icon:      icon,
-	}
+// printTask sends a task message, isComplete marks the one ending the task
+func (l *ChannelLogger) printTask(format string, level string, isComplete bool, words ...interface{}) {
+	msg := newLogMessage(format, "", level, l.useIcons, l.iconSeparator, words...)
+	msg.IsTask = true
+	msg.IsComplete = isComplete
+	l.publish(msg)
+}
 
-	if l.useIcons && icon != "" {
-		msg.Message = fmt.Sprintf("%s %s", icon, msg.Message)
+// publish sends the message to every subscriber whose filter accepts it
+func (l *ChannelLogger) publish(msg LogMessage) {
+	if l.draining.Load() {
+		return // Do nothing while the logger is being drained
 	}
 
-	// Send message to all active subscribers
+	// Hold the read lock for the whole call so Close and Unsubscribe, which take
+	// the write lock, can never close a channel while a send is in progress
 	l.channelMutex.RLock()
 	defer l.channelMutex.RUnlock()
 
+	if len(l.subscribers) == 0 {
+		return // Do nothing if no subscribers
+	}
+
+	msg.SchemaVersion = l.schemaVersion
+	redactMessage(l.redactors, &msg)
+
+	// Send message to all active subscribers
 	for _, sub := range l.subscribers {
 		if sub.filter(msg) { // Use filter instead of id
+			if sub.blocking {
+				select {
+				case sub.channel <- msg:
+				case <-sub.closing:
+					// The subscription is being closed, stop waiting for its reader
+				}
+				continue
+			}
+
 			select {
 			case sub.channel <- msg:
 				// Message sent successfully
 			default:
 				// Channel is full, skip this message for this subscriber
+				sub.dropped.Add(1)
 			}
 		}
 	}
 }
 
+// newLogMessage builds the structured message of the format and words, the
+// icon is prepended to the text when useIcons is set and the text does not
+// already start with an icon
+func newLogMessage(format string, icon LoggerIcon, level string, useIcons bool, iconSeparator string, words ...interface{}) LogMessage {
+	errorMessage := errorField(words)
+	if len(words) > 0 {
+		format = formatMessage(format, words...)
+	}
+
+	msg := LogMessage{
+		Level:     level,
+		Message:   format,
+		Timestamp: nowFunc(),
+		Icon:      icon,
+		Error:     errorMessage,
+	}
+
+	if useIcons && icon != "" && !startsWithIcon(msg.Message) {
+		msg.Message = prependIcon(icon, iconSeparator, msg.Message)
+	}
+	return msg
+}
+
+// structuredFields copies the fields so they marshal as native JSON, values
+// json.Marshal cannot handle, like channels or funcs, are kept as their %v text
+func structuredFields(fields map[string]any) map[string]any {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	result := make(map[string]any, len(fields))
+	for key, value := range fields {
+		if _, err := json.Marshal(value); err != nil {
+			result[key] = fmt.Sprintf("%v", value)
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// errorField returns the messages of any error args joined by "; ", the args
+// themselves are still formatted with %v in the message text
+func errorField(words []interface{}) string {
+	messages := make([]string, 0)
+	for _, word := range words {
+		if err, ok := word.(error); ok && err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	return strings.Join(messages, "; ")
+}
+
 func (l *ChannelLogger) Log(format string, level Level, words ...interface{}) {
 	switch level {
 	case 0:
@@ -118,6 +327,20 @@ func (l *ChannelLogger) Log(format string, level Level, words ...interface{}) {
 	}
 }
 
+// LogFields sends a message carrying the fields to the subscribers, the
+// fields are delivered in LogMessage.Fields
+//
+// Example:
+//
+//	logger.LogFields(log.Info, map[string]any{"user_id": 42}, "user %s logged in", "bob")
+//	// LogMessage{Level: "info", Message: "user bob logged in", Fields: {"user_id": 42}}
+func (l *ChannelLogger) LogFields(level Level, fields map[string]any, format string, words ...interface{}) {
+	if level < Error || level > Trace {
+		return
+	}
+	l.printStructured(correlationIdFromEnv(l.correlationEnv), messageMeta{fields: fields}, format, "", level.String(), words...)
+}
+
 // Log Log information message
 func (l *ChannelLogger) LogIcon(icon LoggerIcon, format string, level Level, words ...interface{}) {
 	switch level {
@@ -167,11 +390,21 @@ func (l *ChannelLogger) Success(format string, words ...interface{}) {
 	l.printMessage(format, IconThumbsUp, "success", words...)
 }
 
+// TaskSuccess log message
+func (l *ChannelLogger) TaskSuccess(format string, isComplete bool, words ...interface{}) {
+	l.printTask(format, "success", isComplete, words...)
+}
+
 // Warn log message
 func (l *ChannelLogger) Warn(format string, words ...interface{}) {
 	l.printMessage(format, IconWarning, "warn", words...)
 }
 
+// TaskWarn log message
+func (l *ChannelLogger) TaskWarn(format string, words ...interface{}) {
+	l.printTask(format, "warn", false, words...)
+}
+
 // Command log message
 func (l *ChannelLogger) Command(format string, words ...interface{}) {
 	l.printMessage(format, IconWrench, "command", words...)
@@ -219,6 +452,11 @@ func (l *ChannelLogger) LogError(message error) {
 	}
 }
 
+// TaskError log message
+func (l *ChannelLogger) TaskError(format string, isComplete bool, words ...interface{}) {
+	l.printTask(format, "error", isComplete, words...)
+}
+
 // Fatal log message
 func (l *ChannelLogger) Fatal(format string, words ...interface{}) {
 	l.printMessage(format, IconRevolvingLight, "error", words...)
@@ -234,6 +472,48 @@ func (l *ChannelLogger) FatalError(e error, format string, words ...interface{})
 
 // Add Subscribe method to ChannelLogger
 func (l *ChannelLogger) Subscribe(id string, callback func(LogMessage) bool) (string, chan LogMessage) {
+	return l.subscribe(id, 0, false, callback)
+}
+
+// SubscribeWithBuffer subscribes like Subscribe with a channel buffer of the
+// given size, messages are dropped for the subscriber while its buffer is full
+func (l *ChannelLogger) SubscribeWithBuffer(id string, bufferSize int, filter func(LogMessage) bool) (string, chan LogMessage) {
+	return l.subscribe(id, bufferSize, false, filter)
+}
+
+// SubscribeBlocking subscribes with a channel buffer of the given size that
+// never drops messages. Once the buffer is full every logging call waits until
+// the subscriber reads, so a slow subscriber slows the whole application and
+// one that stops reading blocks it until it is unsubscribed or the logger is
+// closed, which release the waiting calls. Use it only
+// for subscribers that must see every message and always drain their channel,
+// the dropping subscriptions keep the logging calls fast at the cost of losing
+// messages under load.
+func (l *ChannelLogger) SubscribeBlocking(id string, bufferSize int, filter func(LogMessage) bool) (string, chan LogMessage) {
+	return l.subscribe(id, bufferSize, true, filter)
+}
+
+// SubscribeRange subscribes to the messages whose level is between min and
+// max, both included and in any order, e.g. Warning and Info deliver the warn
+// messages and the info level ones such as notice and success. The filter, when
+// not nil, further selects the messages inside the range.
+func (l *ChannelLogger) SubscribeRange(id string, min, max Level, filter func(LogMessage) bool) (string, chan LogMessage) {
+	if min > max {
+		min, max = max, min
+	}
+
+	return l.subscribe(id, 0, false, func(msg LogMessage) bool {
+		level := levelFromName(msg.Level)
+		if level < min || level > max {
+			return false
+		}
+		return filter == nil || filter(msg)
+	})
+}
+
+// subscribe adds a subscription, a nil filter accepts every message and a
+// buffer size below one uses the logger buffer size
+func (l *ChannelLogger) subscribe(id string, bufferSize int, blocking bool, filter func(LogMessage) bool) (string, chan LogMessage) {
 	l.channelMutex.Lock()
 	defer l.channelMutex.Unlock()
 
@@ -243,7 +523,6 @@ func (l *ChannelLogger) Subscribe(id string, callback func(LogMessage) bool) (st
 
 	// Generate unique ID for this subscription
 	subID := fmt.Sprintf("sub_%s", id)
-	ch := make(chan LogMessage, 100)
 
 	// Check if subscription ID already exists
 	for _, sub := range l.subscribers {
@@ -252,17 +531,76 @@ func (l *ChannelLogger) Subscribe(id string, callback func(LogMessage) bool) (st
 		}
 	}
 
+	if filter == nil {
+		filter = func(LogMessage) bool { return true }
+	}
+	if bufferSize < 1 {
+		bufferSize = l.bufferSize
+	}
+	if bufferSize < 1 {
+		bufferSize = defaultSubscriberBuffer
+	}
+	ch := make(chan LogMessage, bufferSize)
+
 	// Each subscription will get its own channel
-	l.subscribers = append(l.subscribers, Subscriber{
-		id:      subID,
-		filter:  callback,
-		channel: ch,
-	})
+	sub := Subscriber{
+		id:        subID,
+		filter:    filter,
+		channel:   ch,
+		blocking:  blocking,
+		dropped:   &atomic.Uint64{},
+		closing:   make(chan struct{}),
+		closeOnce: &sync.Once{},
+	}
+	l.subscribers = append(l.subscribers, sub)
+
+	l.closingMutex.Lock()
+	if l.closers == nil {
+		l.closers = make(map[string]Subscriber)
+	}
+	l.closers[subID] = sub
+	l.closingMutex.Unlock()
 	return subID, ch
 }
 
+// DroppedCount returns how many messages were dropped for the subscription
+// because its channel was full, or zero for an unknown subscription
+func (l *ChannelLogger) DroppedCount(subscriptionID string) uint64 {
+	l.channelMutex.RLock()
+	defer l.channelMutex.RUnlock()
+
+	for _, sub := range l.subscribers {
+		if sub.id == subscriptionID {
+			return sub.dropped.Load()
+		}
+	}
+	return 0
+}
+
+// SubscriberCount returns the number of active subscriptions
+func (l *ChannelLogger) SubscriberCount() int {
+	l.channelMutex.RLock()
+	defer l.channelMutex.RUnlock()
+
+	return len(l.subscribers)
+}
+
+// SubscriberIDs returns the IDs of the active subscriptions in the order they subscribed
+func (l *ChannelLogger) SubscriberIDs() []string {
+	l.channelMutex.RLock()
+	defer l.channelMutex.RUnlock()
+
+	ids := make([]string, 0, len(l.subscribers))
+	for _, sub := range l.subscribers {
+		ids = append(ids, sub.id)
+	}
+	return ids
+}
+
 // Unsubscribe removes a subscription and closes its channel
 func (l *ChannelLogger) Unsubscribe(subscriptionID string) bool {
+	l.markClosing(func(sub Subscriber) bool { return sub.id == subscriptionID })
+
 	l.channelMutex.Lock()
 	defer l.channelMutex.Unlock()
 
@@ -280,13 +618,63 @@ func (l *ChannelLogger) Unsubscribe(subscriptionID string) bool {
 	return false
 }
 
+// markClosing marks the matching subscriptions as closing, releasing the
+// blocking sends waiting on them. It only takes the closing lock, never the
+// channel lock held by those sends, so it can not wait behind them.
+func (l *ChannelLogger) markClosing(match func(Subscriber) bool) {
+	l.closingMutex.Lock()
+	defer l.closingMutex.Unlock()
+
+	for id, sub := range l.closers {
+		if match(sub) {
+			sub.markClosing()
+			delete(l.closers, id)
+		}
+	}
+}
+
 // Update Channel method to handle the new return signature
 func (l *ChannelLogger) Channel() (string, chan LogMessage) {
 	return l.Subscribe("", func(LogMessage) bool { return true })
 }
 
+// Drain stops accepting new messages, waits up to timeout for the subscribers
+// to read the messages left in their buffers and then closes the logger.
+// Returns false when a buffer still held messages once the timeout elapsed,
+// those messages are dropped. A blocking subscriber that stopped reading does
+// not hold it past the timeout, and the logger accepts messages again for the
+// subscriptions made once it is closed.
+func (l *ChannelLogger) Drain(timeout time.Duration) bool {
+	l.draining.Store(true)
+
+	deadline := time.Now().Add(timeout)
+	drained := l.buffersEmpty()
+	for !drained && time.Now().Before(deadline) {
+		time.Sleep(drainPollInterval)
+		drained = l.buffersEmpty()
+	}
+
+	l.Close()
+	return drained
+}
+
+// buffersEmpty reports whether every subscriber has read all its messages
+func (l *ChannelLogger) buffersEmpty() bool {
+	l.channelMutex.RLock()
+	defer l.channelMutex.RUnlock()
+
+	for _, sub := range l.subscribers {
+		if len(sub.channel) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // Update Close method to handle local subscribers
 func (l *ChannelLogger) Close() {
+	l.markClosing(func(Subscriber) bool { return true })
+
 	l.channelMutex.Lock()
 	defer l.channelMutex.Unlock()
 
@@ -294,4 +682,5 @@ func (l *ChannelLogger) Close() {
 		close(sub.channel)
 	}
 	l.subscribers = nil
+	l.draining.Store(false)
 }
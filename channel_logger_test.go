@@ -1,7 +1,10 @@
 package log
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -36,6 +39,17 @@ func TestLogMessage_String(t *testing.T) {
 			},
 			expected: "[2024-01-01T12:00:00Z] error: error message",
 		},
+		{
+			name: "with fields",
+			message: LogMessage{
+				Level:     "info",
+				Message:   "request handled",
+				Timestamp: fixedTime,
+				Icon:      "📌",
+				Fields:    map[string]any{"user_id": 42, "route": "/login page"},
+			},
+			expected: "[2024-01-01T12:00:00Z] 📌 info: request handled route=\"/login page\" user_id=42",
+		},
 	}
 
 	for _, tt := range tests {
@@ -46,6 +60,49 @@ func TestLogMessage_String(t *testing.T) {
 	}
 }
 
+func TestLogMessage_MarshalJSON(t *testing.T) {
+	timestamp := time.Date(2024, 3, 20, 10, 0, 0, 500000000, time.UTC)
+
+	tests := []struct {
+		name     string
+		msg      LogMessage
+		expected string
+	}{
+		{
+			name:     "required fields",
+			msg:      LogMessage{Level: "info", Message: "hello", Timestamp: timestamp},
+			expected: `{"level":"info","message":"hello","timestamp":"2024-03-20T10:00:00.5Z","icon":"","is_task":false}`,
+		},
+		{
+			name: "optional fields when set",
+			msg: LogMessage{
+				Level:     "error",
+				Message:   "failed",
+				Timestamp: timestamp,
+				Icon:      IconRevolvingLight,
+				IsTask:    true,
+				Error:     "boom",
+				Fields:    map[string]any{"id": 1},
+				Repeat:    3,
+			},
+			expected: `{"level":"error","message":"failed","timestamp":"2024-03-20T10:00:00.5Z","icon":"🚨","is_task":true,"error":"boom","fields":{"id":1},"repeat":3}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.msg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+
+			var decoded LogMessage
+			assert.NoError(t, json.Unmarshal(data, &decoded))
+			assert.True(t, tt.msg.Timestamp.Equal(decoded.Timestamp))
+			assert.Equal(t, tt.msg.Message, decoded.Message)
+		})
+	}
+}
+
 func TestChannelLogger_Init(t *testing.T) {
 	logger := &ChannelLogger{}
 	initialized := logger.Init().(*ChannelLogger)
@@ -109,6 +166,211 @@ func TestChannelLogger_Subscribe(t *testing.T) {
 	})
 }
 
+func TestChannelLogger_SubscribeWithBuffer(t *testing.T) {
+	t.Run("buffer size", func(t *testing.T) {
+		logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		_, ch := logger.SubscribeWithBuffer("", 5, func(msg LogMessage) bool { return true })
+		assert.Equal(t, 5, cap(ch))
+
+		_, defaultCh := logger.Subscribe("", func(msg LogMessage) bool { return true })
+		assert.Equal(t, defaultSubscriberBuffer, cap(defaultCh))
+	})
+
+	t.Run("logger buffer size", func(t *testing.T) {
+		logger := (&ChannelLogger{bufferSize: 10}).Init().(*ChannelLogger)
+		_, ch := logger.Subscribe("", func(msg LogMessage) bool { return true })
+		assert.Equal(t, 10, cap(ch))
+	})
+
+	t.Run("drops when full", func(t *testing.T) {
+		logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		_, ch := logger.SubscribeWithBuffer("", 2, func(msg LogMessage) bool { return true })
+		for i := 0; i < 5; i++ {
+			logger.Info("message %d", i)
+		}
+		assert.Len(t, ch, 2)
+	})
+}
+
+func TestChannelLogger_DroppedCount(t *testing.T) {
+	logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	fullID, _ := logger.SubscribeWithBuffer("full", 2, func(msg LogMessage) bool { return true })
+	drainedID, drained := logger.SubscribeWithBuffer("drained", 2, func(msg LogMessage) bool { return true })
+
+	for i := 0; i < 5; i++ {
+		logger.Info("message %d", i)
+		<-drained
+	}
+
+	assert.Equal(t, uint64(3), logger.DroppedCount(fullID))
+	assert.Equal(t, uint64(0), logger.DroppedCount(drainedID))
+	assert.Equal(t, uint64(0), logger.DroppedCount("sub_unknown"))
+}
+
+func TestChannelLogger_Tasks(t *testing.T) {
+	logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	_, ch := logger.Subscribe("tasks", func(msg LogMessage) bool { return true })
+
+	logger.TaskSuccess("uploaded %d files", true, 10)
+	logger.TaskWarn("skipped %d files", 2)
+	logger.TaskError("upload %s", false, "failed")
+
+	success := <-ch
+	assert.Equal(t, "success", success.Level)
+	assert.Equal(t, "uploaded 10 files", success.Message)
+	assert.True(t, success.IsTask)
+	assert.True(t, success.IsComplete)
+
+	warn := <-ch
+	assert.Equal(t, "warn", warn.Level)
+	assert.True(t, warn.IsTask)
+	assert.False(t, warn.IsComplete)
+
+	failure := <-ch
+	assert.Equal(t, "error", failure.Level)
+	assert.True(t, failure.IsTask)
+	assert.False(t, failure.IsComplete)
+}
+
+func TestChannelLogger_MessageStartingWithIcon(t *testing.T) {
+	logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	logger.UseIcons(true)
+	_, ch := logger.Subscribe("icons", func(msg LogMessage) bool { return true })
+
+	logger.Success("🎉 release %s published", "v1.2.0")
+
+	assert.Equal(t, "🎉 release v1.2.0 published", (<-ch).Message)
+}
+
+func TestChannelLogger_SubscribeRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		min      Level
+		max      Level
+		filter   func(LogMessage) bool
+		expected []string
+	}{
+		{name: "warn to info", min: Warning, max: Info, expected: []string{"warn", "info", "notice", "success"}},
+		{name: "reversed bounds", min: Info, max: Warning, expected: []string{"warn", "info", "notice", "success"}},
+		{name: "single level", min: Error, max: Error, expected: []string{"error"}},
+		{name: "debug and trace", min: Debug, max: Trace, expected: []string{"debug", "trace"}},
+		{
+			name:     "with filter",
+			min:      Warning,
+			max:      Info,
+			filter:   func(msg LogMessage) bool { return msg.Level != "success" },
+			expected: []string{"warn", "info", "notice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+			_, ch := logger.SubscribeRange("range", tt.min, tt.max, tt.filter)
+
+			logger.Error("error")
+			logger.Warn("warn")
+			logger.Info("info")
+			logger.Notice("notice")
+			logger.Success("success")
+			logger.Debug("debug")
+			logger.Trace("trace")
+
+			received := make([]string, 0)
+			for len(ch) > 0 {
+				received = append(received, (<-ch).Level)
+			}
+			assert.Equal(t, tt.expected, received)
+		})
+	}
+}
+
+func TestChannelLogger_SubscribeUnsubscribeStress(t *testing.T) {
+	logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	stop := make(chan struct{})
+
+	var publishers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		publishers.Add(1)
+		go func() {
+			defer publishers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logger.Info("stress message")
+				}
+			}
+		}()
+	}
+
+	var subscribers sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		subscribers.Add(1)
+		go func(worker int) {
+			defer subscribers.Done()
+			for j := 0; j < 50; j++ {
+				var id string
+				var ch chan LogMessage
+				if j%2 == 0 {
+					id, ch = logger.SubscribeBlocking(fmt.Sprintf("blocking-%d-%d", worker, j), 1, nil)
+				} else {
+					id, ch = logger.Channel()
+				}
+				go func() {
+					for range ch {
+					}
+				}()
+				logger.Unsubscribe(id)
+			}
+		}(i)
+	}
+	subscribers.Wait()
+
+	// A blocking subscriber that never reads must not block Close
+	logger.SubscribeBlocking("stalled", 1, nil)
+	done := make(chan struct{})
+	go func() {
+		logger.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on a stalled blocking subscriber")
+	}
+
+	close(stop)
+	publishers.Wait()
+}
+
+func TestChannelLogger_SubscribeBlocking(t *testing.T) {
+	logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	_, ch := logger.SubscribeBlocking("", 1, func(msg LogMessage) bool { return true })
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			logger.Info("message %d", i)
+		}
+		close(done)
+	}()
+
+	received := make([]string, 0)
+	for i := 0; i < 5; i++ {
+		select {
+		case msg := <-ch:
+			received = append(received, msg.Message)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for message")
+		}
+	}
+	<-done
+
+	assert.Equal(t, []string{"message 0", "message 1", "message 2", "message 3", "message 4"}, received)
+}
+
 func TestChannelLogger_Unsubscribe(t *testing.T) {
 	logger := &ChannelLogger{}
 	logger = logger.Init().(*ChannelLogger)
@@ -201,6 +463,127 @@ func TestChannelLogger_Close(t *testing.T) {
 	assert.False(t, logger.Unsubscribe(id2))
 }
 
+func TestChannelLogger_Drain(t *testing.T) {
+	t.Run("waits for the buffers to be read", func(t *testing.T) {
+		logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		_, ch := logger.Subscribe("reader", nil)
+		logger.Info("first")
+		logger.Info("second")
+
+		received := make(chan []string)
+		go func() {
+			messages := make([]string, 0)
+			for msg := range ch {
+				time.Sleep(10 * time.Millisecond)
+				messages = append(messages, msg.Message)
+			}
+			received <- messages
+		}()
+
+		assert.True(t, logger.Drain(time.Second))
+		logger.Info("after drain")
+		assert.Equal(t, []string{"first", "second"}, <-received)
+		assert.Nil(t, logger.subscribers)
+	})
+
+	t.Run("gives up after the timeout", func(t *testing.T) {
+		logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		_, ch := logger.Subscribe("stalled", nil)
+		logger.Info("never read")
+
+		assert.False(t, logger.Drain(20*time.Millisecond))
+		msg, ok := <-ch
+		assert.True(t, ok)
+		assert.Equal(t, "never read", msg.Message)
+		_, ok = <-ch
+		assert.False(t, ok)
+	})
+
+	t.Run("releases a stalled blocking subscriber", func(t *testing.T) {
+		logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		logger.SubscribeBlocking("stalled", 1, nil)
+		logger.Info("fills the buffer")
+		sent := make(chan struct{})
+		go func() {
+			logger.Info("waits for the reader")
+			close(sent)
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		drained := make(chan bool)
+		go func() { drained <- logger.Drain(20 * time.Millisecond) }()
+		select {
+		case ok := <-drained:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("Drain blocked behind the stalled subscriber")
+		}
+		<-sent
+	})
+
+	t.Run("accepts new subscriptions once drained", func(t *testing.T) {
+		logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		logger.Subscribe("before", nil)
+		assert.True(t, logger.Drain(time.Millisecond))
+
+		_, ch := logger.Subscribe("after", nil)
+		logger.Info("after drain")
+		msg := <-ch
+		assert.Equal(t, "after drain", msg.Message)
+		logger.Close()
+	})
+}
+
+func TestLoggerService_DrainChannels(t *testing.T) {
+	channel := (&ChannelLogger{}).Init().(*ChannelLogger)
+	service := &LoggerService{LogLevel: Info, Loggers: []Logger{channel}}
+	_, ch := channel.Subscribe("reader", nil)
+	service.Info("pending")
+
+	go func() {
+		for range ch {
+		}
+	}()
+
+	assert.True(t, service.DrainChannels(time.Second))
+	assert.Equal(t, 0, channel.SubscriberCount())
+}
+
+func TestChannelLogger_ConcurrentCloseAndLog(t *testing.T) {
+	for round := 0; round < 50; round++ {
+		logger := &ChannelLogger{}
+		logger = logger.Init().(*ChannelLogger)
+		for i := 0; i < 5; i++ {
+			_, ch := logger.Subscribe("", func(msg LogMessage) bool { return true })
+			go func() {
+				for range ch {
+				}
+			}()
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					logger.Info("message %d", j)
+				}
+			}()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Close()
+		}()
+
+		// a send on a closed channel would panic and abort the test binary
+		wg.Wait()
+		assert.Nil(t, logger.subscribers)
+	}
+}
+
 func TestChannelLogger_LoggingMethods(t *testing.T) {
 	logger := &ChannelLogger{}
 	logger = logger.Init().(*ChannelLogger)
@@ -1238,3 +1621,216 @@ func TestChannelLogger_Fatal(t *testing.T) {
 		})
 	}
 }
+
+func TestChannelLogger_SchemaVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		version  string
+		expected string
+	}{
+		{name: "disabled", enabled: false, expected: ""},
+		{name: "explicit version", enabled: true, version: "2", expected: "2"},
+		{name: "default version", enabled: true, version: "", expected: LogMessageSchemaVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+			service := &LoggerService{
+				LogLevel: Info,
+				Loggers:  []Logger{logger},
+			}
+			if tt.enabled {
+				service.WithSchemaVersion(tt.version)
+			}
+			_, ch := logger.Subscribe("schema", func(LogMessage) bool { return true })
+
+			service.Info("versioned")
+
+			msg := <-ch
+			data, err := json.Marshal(msg)
+			assert.NoError(t, err)
+
+			var decoded map[string]interface{}
+			assert.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, "versioned", decoded["message"])
+			if tt.expected == "" {
+				assert.NotContains(t, decoded, "schema_version")
+			} else {
+				assert.Equal(t, tt.expected, decoded["schema_version"])
+			}
+		})
+	}
+}
+
+func TestChannelLogger_ErrorField(t *testing.T) {
+	tests := []struct {
+		name            string
+		format          string
+		words           []interface{}
+		expectedMessage string
+		expectedError   string
+	}{
+		{
+			name:            "no error args",
+			format:          "count %d",
+			words:           []interface{}{1},
+			expectedMessage: "count 1",
+		},
+		{
+			name:            "single error arg",
+			format:          "request failed: %v",
+			words:           []interface{}{errors.New("timeout")},
+			expectedMessage: "request failed: timeout",
+			expectedError:   "timeout",
+		},
+		{
+			name:            "multiple error args",
+			format:          "%v then %v for %s",
+			words:           []interface{}{errors.New("first"), errors.New("second"), "job"},
+			expectedMessage: "first then second for job",
+			expectedError:   "first; second",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+			_, ch := logger.Subscribe("errors", func(LogMessage) bool { return true })
+
+			logger.Info(tt.format, tt.words...)
+
+			msg := <-ch
+			assert.Equal(t, tt.expectedMessage, msg.Message)
+			assert.Equal(t, tt.expectedError, msg.Error)
+
+			data, err := json.Marshal(msg)
+			assert.NoError(t, err)
+
+			var decoded map[string]interface{}
+			assert.NoError(t, json.Unmarshal(data, &decoded))
+			if tt.expectedError == "" {
+				assert.NotContains(t, decoded, "error")
+			} else {
+				assert.Equal(t, tt.expectedError, decoded["error"])
+			}
+		})
+	}
+}
+
+func TestChannelLogger_Source(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "without source", source: ""},
+		{name: "with source", source: "auth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+			service := &LoggerService{
+				LogLevel: Info,
+				Loggers:  []Logger{logger},
+			}
+			service.WithSource(tt.source)
+			_, ch := logger.Subscribe("source", func(LogMessage) bool { return true })
+
+			service.Info("user logged in")
+
+			msg := <-ch
+			assert.Equal(t, tt.source, msg.Source)
+			assert.Equal(t, "user logged in", msg.Message)
+
+			data, err := json.Marshal(msg)
+			assert.NoError(t, err)
+
+			var decoded map[string]interface{}
+			assert.NoError(t, json.Unmarshal(data, &decoded))
+			if tt.source == "" {
+				assert.NotContains(t, decoded, "logger")
+			} else {
+				assert.Equal(t, tt.source, decoded["logger"])
+			}
+		})
+	}
+}
+
+func TestLoggerService_ChannelLoggerFollowsLevel(t *testing.T) {
+	service := NewIsolated()
+	defer service.Close()
+	messages := make(chan LogMessage, 10)
+	service.OnMessage("follow", func(msg LogMessage) { messages <- msg })
+
+	service.Debug("hidden at info")
+	service.WithDebug()
+	service.Debug("debug after WithDebug")
+	service.LogLevel = Trace
+	service.Trace("trace after setting LogLevel")
+	restore := service.WithLevelScope(Info)
+	service.Debug("hidden inside the scope")
+	restore()
+
+	for _, expected := range []string{"debug after WithDebug", "trace after setting LogLevel"} {
+		select {
+		case msg := <-messages:
+			assert.Equal(t, expected, msg.Message)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %q", expected)
+		}
+	}
+	select {
+	case msg := <-messages:
+		t.Fatalf("unexpected message %q", msg.Message)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestChannelLogger_LogFields(t *testing.T) {
+	logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	defer logger.Close()
+	_, ch := logger.Subscribe("fields", nil)
+
+	logger.LogFields(Warning, map[string]any{"user_id": 42}, "user %s locked out", "bob")
+	logger.LogFields(Level(10), map[string]any{"ignored": true}, "ignored")
+	logger.Info("plain")
+
+	msg := <-ch
+	assert.Equal(t, "warn", msg.Level)
+	assert.Equal(t, "user bob locked out", msg.Message)
+	assert.Equal(t, map[string]any{"user_id": 42}, msg.Fields)
+
+	msg = <-ch
+	assert.Equal(t, "plain", msg.Message)
+	assert.Nil(t, msg.Fields)
+}
+
+func TestChannelLogger_SetIconSeparator(t *testing.T) {
+	logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	defer logger.Close()
+	_, ch := logger.Subscribe("icons", nil)
+	logger.UseIcons(true)
+	logger.SetIconSeparator("\t")
+
+	logger.Warn("careful")
+	msg := <-ch
+	assert.Equal(t, string(IconWarning)+"\tcareful", msg.Message)
+}
+
+func TestChannelLogger_SubscriberIDs(t *testing.T) {
+	logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	defer logger.Close()
+	assert.Equal(t, 0, logger.SubscriberCount())
+	assert.Empty(t, logger.SubscriberIDs())
+
+	first, _ := logger.Subscribe("first", nil)
+	second, _ := logger.Subscribe("second", nil)
+	assert.Equal(t, 2, logger.SubscriberCount())
+	assert.Equal(t, []string{first, second}, logger.SubscriberIDs())
+
+	logger.Unsubscribe(first)
+	assert.Equal(t, 1, logger.SubscriberCount())
+	assert.Equal(t, []string{second}, logger.SubscriberIDs())
+}
@@ -0,0 +1,29 @@
+package log
+
+import (
+	"fmt"
+	"time"
+)
+
+// nowFunc returns the current time, tests replace it to freeze the clock
+var nowFunc = time.Now
+
+// clockNow returns the time of the clock set on a logger, or nowFunc when the
+// logger has none
+func clockNow(clock func() time.Time) time.Time {
+	if clock != nil {
+		return clock()
+	}
+	return nowFunc()
+}
+
+// formatTimestamp renders the timestamp prefix of a message at now, when
+// startedAt is set the elapsed time since then is rendered instead of the
+// wall-clock time
+func formatTimestamp(now time.Time, startedAt time.Time) string {
+	if startedAt.IsZero() {
+		return now.Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("+%.3fs", now.Sub(startedAt).Seconds())
+}
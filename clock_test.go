@@ -0,0 +1,60 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTimestamp(t *testing.T) {
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	t.Run("wall-clock when no start time", func(t *testing.T) {
+		assert.Equal(t, "2024-03-20T10:00:00Z", formatTimestamp(nowFunc(), time.Time{}))
+	})
+
+	t.Run("uptime since start time", func(t *testing.T) {
+		assert.Equal(t, "+0.123s", formatTimestamp(nowFunc(), now.Add(-123*time.Millisecond)))
+	})
+}
+
+func TestSetClock(t *testing.T) {
+	frozen := func() time.Time { return time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC) }
+
+	t.Run("command line logger", func(t *testing.T) {
+		var output bytes.Buffer
+		logger := &CmdLogger{writer: &output, noColors: true}
+		logger.UseTimestamp(true)
+		logger.SetClock(frozen)
+
+		logger.Info("frozen")
+		assert.Equal(t, "2024-03-20T10:00:00Z frozen\n", output.String())
+	})
+
+	t.Run("file logger", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "clock.log")
+		logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+		logger.UseTimestamp(true)
+		logger.SetClock(frozen)
+
+		logger.Info("frozen")
+		logger.Close()
+
+		content, err := os.ReadFile(logFile)
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-03-20T10:00:00Z frozen\n", string(content))
+	})
+
+	t.Run("nil restores the wall clock", func(t *testing.T) {
+		logger := &CmdLogger{}
+		logger.SetClock(frozen)
+		logger.SetClock(nil)
+		assert.WithinDuration(t, time.Now(), clockNow(logger.clock), time.Minute)
+	})
+}
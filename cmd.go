@@ -1,10 +1,12 @@
 package log
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	strcolor "github.com/cjlapao/common-go/strcolor"
@@ -15,7 +17,17 @@ type CmdLogger struct {
 	useTimestamp      bool
 	userCorrelationId bool
 	useIcons          bool
+	correlationEnv    string
+	iconSeparator     string
+	showLevel         bool
+	uptimeStart       time.Time
+	clock             func() time.Time
 	writer            io.Writer
+	redactors         []Redactor
+	levelColors       map[Level]ColorCode
+	noColors          bool
+	colorsSet         bool
+	tee               *teeWriter
 }
 
 func (l CmdLogger) Init() Logger {
@@ -24,9 +36,52 @@ func (l CmdLogger) Init() Logger {
 		userCorrelationId: false,
 		useIcons:          false,
 		writer:            os.Stdout,
+		noColors:          !colorTerminal(os.Stdout),
+		tee:               &teeWriter{},
 	}
 }
 
+// SetWriter sets the writer the messages are written to, colors are disabled
+// when the writer is not a terminal unless they were set with UseColors or ForceColors
+func (l *CmdLogger) SetWriter(w io.Writer) {
+	l.writer = w
+	if !l.colorsSet {
+		l.noColors = !colorTerminal(w)
+	}
+}
+
+// AddWriter adds a writer every message is also written to, colors are used
+// when the writer is a terminal unless they were set with UseColors or ForceColors
+func (l *CmdLogger) AddWriter(w io.Writer) {
+	if l.tee == nil {
+		l.tee = &teeWriter{}
+	}
+	l.tee.add(w)
+}
+
+// RemoveWriter removes a writer added with AddWriter
+func (l *CmdLogger) RemoveWriter(w io.Writer) {
+	if l.tee != nil {
+		l.tee.remove(w)
+	}
+}
+
+// UseColors enables or disables the colors, whatever the writer is
+func (l *CmdLogger) UseColors(value bool) {
+	l.colorsSet = true
+	l.noColors = !value
+}
+
+// ForceColors forces the colors on even when the writer is not a terminal,
+// such as a CI log that renders them, false restores the auto-detection
+func (l *CmdLogger) ForceColors(value bool) {
+	l.colorsSet = value
+	if value {
+		enableVirtualTerminal(l.writer)
+	}
+	l.noColors = !value && !colorTerminal(l.writer)
+}
+
 func (l *CmdLogger) IsTimestampEnabled() bool {
 	return l.useTimestamp
 }
@@ -35,6 +90,18 @@ func (l *CmdLogger) UseTimestamp(value bool) {
 	l.useTimestamp = value
 }
 
+// UseUptimeTimestamp renders timestamps as the elapsed time since startedAt,
+// a zero startedAt restores the wall-clock timestamps
+func (l *CmdLogger) UseUptimeTimestamp(startedAt time.Time) {
+	l.uptimeStart = startedAt
+}
+
+// SetClock sets the clock the timestamps are read from, so tests can freeze
+// the time, nil restores the wall clock
+func (l *CmdLogger) SetClock(clock func() time.Time) {
+	l.clock = clock
+}
+
 func (l *CmdLogger) UseCorrelationId(value bool) {
 	l.userCorrelationId = value
 }
@@ -43,19 +110,59 @@ func (l *CmdLogger) UseIcons(value bool) {
 	l.useIcons = value
 }
 
+// SetCorrelationEnv sets the environment variable the correlation id is read
+// from, CORRELATION_ID when empty
+func (l *CmdLogger) SetCorrelationEnv(name string) {
+	l.correlationEnv = name
+}
+
+// SetIconSeparator sets the text between the icon and the message, such as two
+// spaces or a tab for wide emoji, an empty separator restores the single space
+func (l *CmdLogger) SetIconSeparator(separator string) {
+	l.iconSeparator = separator
+}
+
+// ShowLevelLabel prefixes every message with its uppercase level, e.g. ERROR:,
+// after the timestamp and before the correlation id and icon, so the level is
+// still readable when the colors are stripped
+func (l *CmdLogger) ShowLevelLabel(value bool) {
+	l.showLevel = value
+}
+
+// SetRedactors sets the redactors run on every message before it is written
+func (l *CmdLogger) SetRedactors(redactors []Redactor) {
+	l.redactors = redactors
+}
+
+// SetLevelColor overrides the color of the messages logged at the level, the
+// other message kinds logged at info, such as success or notice, keep their colors
+func (l *CmdLogger) SetLevelColor(level Level, code ColorCode) {
+	if l.levelColors == nil {
+		l.levelColors = make(map[Level]ColorCode)
+	}
+	l.levelColors[level] = code
+}
+
+// levelColor returns the color override for the level name, if any
+func (l *CmdLogger) levelColor(level string) (ColorCode, bool) {
+	parsed := levelFromName(level)
+	code, ok := l.levelColors[parsed]
+	return code, ok && parsed.String() == level
+}
+
 // Log Log information message
 func (l *CmdLogger) Log(format string, level Level, words ...interface{}) {
 	switch level {
 	case 0:
-		l.printMessage(format, "", "error", words...)
+		l.printMessage(format, "", "error", correlationIdFromEnv(l.correlationEnv), words...)
 	case 1:
-		l.printMessage(format, "", "warn", words...)
+		l.printMessage(format, "", "warn", correlationIdFromEnv(l.correlationEnv), words...)
 	case 2:
-		l.printMessage(format, "", "info", words...)
+		l.printMessage(format, "", "info", correlationIdFromEnv(l.correlationEnv), words...)
 	case 3:
-		l.printMessage(format, "", "debug", words...)
+		l.printMessage(format, "", "debug", correlationIdFromEnv(l.correlationEnv), words...)
 	case 4:
-		l.printMessage(format, "", "trace", words...)
+		l.printMessage(format, "", "trace", correlationIdFromEnv(l.correlationEnv), words...)
 	}
 }
 
@@ -63,15 +170,15 @@ func (l *CmdLogger) Log(format string, level Level, words ...interface{}) {
 func (l *CmdLogger) LogIcon(icon LoggerIcon, format string, level Level, words ...interface{}) {
 	switch level {
 	case 0:
-		l.printMessage(format, icon, "error", words...)
+		l.printMessage(format, icon, "error", correlationIdFromEnv(l.correlationEnv), words...)
 	case 1:
-		l.printMessage(format, icon, "warn", words...)
+		l.printMessage(format, icon, "warn", correlationIdFromEnv(l.correlationEnv), words...)
 	case 2:
-		l.printMessage(format, icon, "info", words...)
+		l.printMessage(format, icon, "info", correlationIdFromEnv(l.correlationEnv), words...)
 	case 3:
-		l.printMessage(format, icon, "debug", words...)
+		l.printMessage(format, icon, "debug", correlationIdFromEnv(l.correlationEnv), words...)
 	case 4:
-		l.printMessage(format, icon, "trace", words...)
+		l.printMessage(format, icon, "trace", correlationIdFromEnv(l.correlationEnv), words...)
 	}
 }
 
@@ -85,61 +192,72 @@ func (l *CmdLogger) LogHighlight(format string, level Level, highlightColor strc
 
 	switch level {
 	case 0:
-		l.printMessage(format, "", "error", words...)
+		l.printMessage(format, "", "error", correlationIdFromEnv(l.correlationEnv), words...)
 	case 1:
-		l.printMessage(format, "", "warn", words...)
+		l.printMessage(format, "", "warn", correlationIdFromEnv(l.correlationEnv), words...)
 	case 2:
-		l.printMessage(format, "", "info", words...)
+		l.printMessage(format, "", "info", correlationIdFromEnv(l.correlationEnv), words...)
 	case 3:
-		l.printMessage(format, "", "debug", words...)
+		l.printMessage(format, "", "debug", correlationIdFromEnv(l.correlationEnv), words...)
 	case 4:
-		l.printMessage(format, "", "trace", words...)
+		l.printMessage(format, "", "trace", correlationIdFromEnv(l.correlationEnv), words...)
 	}
 }
 
 // Info log information message
 func (l *CmdLogger) Info(format string, words ...interface{}) {
-	l.printMessage(format, IconInfo, "info", words...)
+	l.printMessage(format, IconInfo, "info", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Success log message
 func (l *CmdLogger) Success(format string, words ...interface{}) {
-	l.printMessage(format, IconThumbsUp, "success", words...)
+	l.printMessage(format, IconThumbsUp, "success", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// TaskSuccess log message
+func (l *CmdLogger) TaskSuccess(format string, isComplete bool, words ...interface{}) {
+	format, words = taskMessage(format, isComplete, words)
+	l.printMessage(format, "", "success", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Warn log message
 func (l *CmdLogger) Warn(format string, words ...interface{}) {
-	l.printMessage(format, IconWarning, "warn", words...)
+	l.printMessage(format, IconWarning, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// TaskWarn log message
+func (l *CmdLogger) TaskWarn(format string, words ...interface{}) {
+	l.printMessage(format, "", "warn", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Command log message
 func (l *CmdLogger) Command(format string, words ...interface{}) {
-	l.printMessage(format, IconWrench, "command", words...)
+	l.printMessage(format, IconWrench, "command", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Disabled log message
 func (l *CmdLogger) Disabled(format string, words ...interface{}) {
-	l.printMessage(format, IconBlackSquare, "disabled", words...)
+	l.printMessage(format, IconBlackSquare, "disabled", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Notice log message
 func (l *CmdLogger) Notice(format string, words ...interface{}) {
-	l.printMessage(format, IconFlag, "notice", words...)
+	l.printMessage(format, IconFlag, "notice", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Debug log message
 func (l *CmdLogger) Debug(format string, words ...interface{}) {
-	l.printMessage(format, IconFire, "debug", words...)
+	l.printMessage(format, IconFire, "debug", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Trace log message
 func (l *CmdLogger) Trace(format string, words ...interface{}) {
-	l.printMessage(format, IconBulb, "trace", words...)
+	l.printMessage(format, IconBulb, "trace", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Error log message
 func (l *CmdLogger) Error(format string, words ...interface{}) {
-	l.printMessage(format, IconRevolvingLight, "error", words...)
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Error log message
@@ -149,106 +267,221 @@ func (l *CmdLogger) Exception(err error, format string, words ...interface{}) {
 	} else {
 		format = format + ", err " + err.Error()
 	}
-	l.printMessage(format, IconRevolvingLight, "error", words...)
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // LogError log message
 func (l *CmdLogger) LogError(message error) {
 	if message != nil {
-		l.printMessage(message.Error(), IconRevolvingLight, "error")
+		l.printMessage(message.Error(), IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv))
 	}
 }
 
+// TaskError log message
+func (l *CmdLogger) TaskError(format string, isComplete bool, words ...interface{}) {
+	format, words = taskMessage(format, isComplete, words)
+	l.printMessage(format, "", "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
 // Fatal log message
 func (l *CmdLogger) Fatal(format string, words ...interface{}) {
-	l.printMessage(format, IconRevolvingLight, "error", words...)
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // FatalError log message
 func (l *CmdLogger) FatalError(e error, format string, words ...interface{}) {
 	l.Error(format, words...)
+	l.Flush()
 	if e != nil {
 		panic(e)
 	}
 }
 
+// Flush flushes the writer when it buffers its output
+func (l *CmdLogger) Flush() error {
+	err := flushWriter(l.writer)
+	if l.tee != nil {
+		err = errors.Join(err, l.tee.flush())
+	}
+	return err
+}
+
+// printCorrelated prints a message using a correlation id already resolved by the caller
+func (l *CmdLogger) printCorrelated(correlationId string, format string, icon LoggerIcon, level string, words ...interface{}) {
+	l.printMessage(format, icon, level, correlationId, words...)
+}
+
 // printMessage Prints a message in the system
-func (l *CmdLogger) printMessage(format string, icon LoggerIcon, level string, words ...interface{}) {
+func (l *CmdLogger) printMessage(format string, icon LoggerIcon, level string, correlationId string, words ...interface{}) {
+	l.writeMessage(l.composeMessage(format, icon, level, correlationId, words...), level, "\n")
+}
+
+// Inline prints a message without the trailing newline, the color is still
+// reset, so the next write can return to the start of the line with \r and
+// overwrite it. Use Newline to finalize the line.
+//
+// Example:
+//
+//	for i := 0; i <= 100; i += 10 {
+//		logger.Inline("\rDownloading %d%%", log.Info, i)
+//	}
+//	logger.Newline()
+func (l *CmdLogger) Inline(format string, level Level, words ...interface{}) {
+	if level < Error || level > Trace {
+		return
+	}
+	l.writeMessage(l.composeMessage(format, "", level.String(), correlationIdFromEnv(l.correlationEnv), words...), level.String(), "")
+}
+
+// Newline ends the line left open by Inline
+func (l *CmdLogger) Newline() {
+	l.write(func(bool) string { return "\n" })
+}
+
+// composeMessage formats the message and adds the icon, correlation id, level
+// label and timestamp the logger is configured with
+func (l *CmdLogger) composeMessage(format string, icon LoggerIcon, level string, correlationId string, words ...interface{}) string {
 	// First format the arguments according to the format string
-	message := fmt.Sprintf(format, words...)
+	message := formatMessage(format, words...)
 
-	if l.useIcons && icon != "" {
-		message = fmt.Sprintf("%s %s", icon, message)
+	if l.useIcons && icon != "" && !startsWithIcon(message) {
+		message = prependIcon(icon, l.iconSeparator, message)
 	}
 
-	if l.userCorrelationId {
-		correlationId := os.Getenv("CORRELATION_ID")
-		if correlationId != "" {
-			message = "[" + correlationId + "] " + message
-		}
+	if l.userCorrelationId && correlationId != "" {
+		message = "[" + correlationId + "] " + message
+	}
+
+	if l.showLevel {
+		message = strings.ToUpper(level) + ": " + message
 	}
 
 	if l.useTimestamp {
-		message = fmt.Sprintf("%s %s", time.Now().Format(time.RFC3339), message)
+		message = fmt.Sprintf("%s %s", formatTimestamp(clockNow(l.clock), l.uptimeStart), message)
 	}
 
-	message = message + "\u001b[0m" + "\n"
+	return redact(l.redactors, message)
+}
+
+// writeMessage writes the message in the color of the level followed by end,
+// messages of unknown levels are dropped
+func (l *CmdLogger) writeMessage(message string, level string, end string) {
+	level = strings.ToLower(level)
+	color, ok := levelColorSequences[level]
+	if !ok {
+		return
+	}
 
-	// Use the appropriate color writer for each log level
-	switch strings.ToLower(level) {
-	case "success":
-		successWriter(l.writer, message)
-	case "warn":
-		warningWriter(l.writer, message)
-	case "error":
-		errorWriter(l.writer, message)
-	case "debug":
-		debugWriter(l.writer, message)
-	case "trace":
-		traceWriter(l.writer, message)
-	case "info":
-		infoWriter(l.writer, message)
-	case "notice":
-		noticeWriter(l.writer, message)
-	case "command":
-		commandWriter(l.writer, message)
-	case "disabled":
-		disableWriter(l.writer, message)
+	if code, ok := l.levelColor(level); ok {
+		color = colorSequence(code)
 	}
-}
 
-func successWriter(w io.Writer, message string) {
-	fmt.Fprintf(w, "\u001b[32m%s", message)
+	l.write(func(noColors bool) string {
+		if noColors {
+			return stripColors(message) + end
+		}
+		return colorLines(color, message) + end
+	})
 }
 
-func warningWriter(w io.Writer, message string) {
-	fmt.Fprintf(w, "\u001b[33m%s", message)
+// write writes the text rendered for each writer color choice to the writer
+// and to the writers added with AddWriter, each in a single write
+func (l *CmdLogger) write(render func(noColors bool) string) {
+	fmt.Fprint(l.writer, render(l.noColors))
+	if l.tee != nil {
+		l.tee.write(render, l.colorsSet, l.noColors)
+	}
 }
 
-func errorWriter(w io.Writer, message string) {
-	fmt.Fprintf(w, "\u001b[31m%s", message)
+// colorReset is the ANSI sequence restoring the default terminal color
+const colorReset = "\u001b[0m"
+
+// levelColorSequences are the default colors of each level name, info uses
+// the default color, so its lines open with a reset
+var levelColorSequences = map[string]string{
+	"success":  "\u001b[32m",
+	"warn":     "\u001b[33m",
+	"error":    "\u001b[31m",
+	"debug":    "\u001b[36m",
+	"trace":    "\u001b[37m",
+	"info":     colorReset,
+	"notice":   "\u001b[34m",
+	"command":  "\u001b[35m",
+	"disabled": "\u001b[90m",
+}
+
+// colorLines colors every line of the message, each line starts with its color
+// and ends with a reset, so a line never depends on or leaks color into the
+// output of another writer.
+func colorLines(color string, message string) string {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		lines[i] = color + line + colorReset
+	}
+
+	return strings.Join(lines, "\n")
 }
 
-func debugWriter(w io.Writer, message string) {
-	fmt.Fprintf(w, "\u001b[36m%s", message)
+// teeWriter holds the extra writers of a CmdLogger, each remembering whether
+// it is a terminal so it gets colors of its own
+type teeWriter struct {
+	writers []teeTarget
+	mutex   sync.RWMutex
 }
 
-func traceWriter(w io.Writer, message string) {
-	fmt.Fprintf(w, "\u001b[37m%s", message)
+// teeTarget is a writer added with AddWriter
+type teeTarget struct {
+	writer   io.Writer
+	noColors bool
 }
 
-func infoWriter(w io.Writer, message string) {
-	fmt.Fprintf(w, "\u001b[0m%s", message)
+// add adds the writer detecting whether it is a terminal
+func (t *teeWriter) add(w io.Writer) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.writers = append(t.writers, teeTarget{writer: w, noColors: !colorTerminal(w)})
 }
 
-func noticeWriter(w io.Writer, message string) {
-	fmt.Fprintf(w, "\u001b[34m%s", message)
+// remove removes every occurrence of the writer
+func (t *teeWriter) remove(w io.Writer) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	writers := make([]teeTarget, 0, len(t.writers))
+	for _, target := range t.writers {
+		if target.writer != w {
+			writers = append(writers, target)
+		}
+	}
+	t.writers = writers
 }
 
-func commandWriter(w io.Writer, message string) {
-	fmt.Fprintf(w, "\u001b[35m%s", message)
+// write writes the rendered text to every writer, when colorsSet the colors
+// chosen for the logger apply to all of them
+func (t *teeWriter) write(render func(noColors bool) string, colorsSet bool, noColors bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	for _, target := range t.writers {
+		if colorsSet {
+			fmt.Fprint(target.writer, render(noColors))
+		} else {
+			fmt.Fprint(target.writer, render(target.noColors))
+		}
+	}
 }
 
-func disableWriter(w io.Writer, message string) {
-	fmt.Fprintf(w, "\u001b[90m%s", message)
+// flush flushes every writer that buffers its output
+func (t *teeWriter) flush() error {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	var errs []error
+	for _, target := range t.writers {
+		if err := flushWriter(target.writer); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -1,9 +1,12 @@
 package log
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"testing"
 
 	strcolor "github.com/cjlapao/common-go/strcolor"
@@ -1621,3 +1624,364 @@ func TestCmdLogger_FatalError(t *testing.T) {
 		})
 	}
 }
+
+func TestCmdLogger_FatalErrorFlushesBufferedWriter(t *testing.T) {
+	var output bytes.Buffer
+	buffered := bufio.NewWriter(&output)
+	l := &CmdLogger{writer: buffered}
+	err := fmt.Errorf("test error")
+
+	func() {
+		defer func() {
+			assert.Equal(t, err, recover())
+		}()
+		l.FatalError(err, "Operation failed")
+	}()
+
+	assert.Zero(t, buffered.Buffered())
+	assert.Equal(t, "\x1b[31mOperation failed\x1b[0m\n", output.String())
+}
+
+func TestCmdLogger_LinesAreSelfContained(t *testing.T) {
+	var output bytes.Buffer
+	first := &CmdLogger{writer: &output}
+	second := &CmdLogger{writer: &output}
+
+	first.Warn("first warning")
+	second.Info("second info")
+	first.Error("multi\nline")
+	second.Success("second success")
+
+	expected := []string{
+		"\x1b[33mfirst warning\x1b[0m",
+		"\x1b[0msecond info\x1b[0m",
+		"\x1b[31mmulti\x1b[0m",
+		"\x1b[31mline\x1b[0m",
+		"\x1b[32msecond success\x1b[0m",
+	}
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Equal(t, expected, lines)
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "\x1b["), "line %q does not open with a color", line)
+		assert.True(t, strings.HasSuffix(line, "\x1b[0m"), "line %q does not end with a reset", line)
+	}
+}
+
+func TestCmdLogger_Tasks(t *testing.T) {
+	var output bytes.Buffer
+	l := &CmdLogger{writer: &output, useIcons: true}
+
+	l.TaskSuccess("uploaded %d files", true, 10)
+	l.TaskWarn("skipped %d files", 2)
+	l.TaskError("upload %s", false, "failed")
+
+	expected := []string{
+		"\x1b[32muploaded 10 files [done]\x1b[0m",
+		"\x1b[33mskipped 2 files\x1b[0m",
+		"\x1b[31mupload failed\x1b[0m",
+	}
+	assert.Equal(t, expected, strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n"))
+}
+
+func TestCmdLogger_MessageStartingWithIcon(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{name: "emoji", format: "🚀 deploying", expected: "\x1b[0m🚀 deploying\x1b[0m\n"},
+		{name: "known icon", format: string(IconInfo) + " ready", expected: "\x1b[0mℹ ready\x1b[0m\n"},
+		{name: "plain text", format: "ready", expected: "\x1b[0mℹ ready\x1b[0m\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			l := &CmdLogger{writer: &output, useIcons: true}
+
+			l.Info(tt.format)
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestCmdLogger_SetLevelColor(t *testing.T) {
+	tests := []struct {
+		name     string
+		colors   map[Level]ColorCode
+		log      func(l *CmdLogger)
+		expected string
+	}{
+		{
+			name:     "default colors without overrides",
+			log:      func(l *CmdLogger) { l.Warn("careful") },
+			expected: "\x1b[33mcareful\x1b[0m\n",
+		},
+		{
+			name:     "override replaces the level color",
+			colors:   map[Level]ColorCode{Warning: Magenta},
+			log:      func(l *CmdLogger) { l.Warn("careful") },
+			expected: "\x1b[35mcareful\x1b[0m\n",
+		},
+		{
+			name:     "override of another level keeps the default",
+			colors:   map[Level]ColorCode{Error: BrightRed},
+			log:      func(l *CmdLogger) { l.Debug("details") },
+			expected: "\x1b[36mdetails\x1b[0m\n",
+		},
+		{
+			name:     "info override keeps the success color",
+			colors:   map[Level]ColorCode{Info: Black},
+			log:      func(l *CmdLogger) { l.Info("hello"); l.Success("done") },
+			expected: "\x1b[30mhello\x1b[0m\n\x1b[32mdone\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			l := &CmdLogger{writer: &output}
+			for level, code := range tt.colors {
+				l.SetLevelColor(level, code)
+			}
+
+			tt.log(l)
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestCmdLogger_ColorDetection(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal bool
+		setup    func(l *CmdLogger, w io.Writer)
+		expected string
+	}{
+		{
+			name:     "colors on a terminal",
+			terminal: true,
+			setup:    func(l *CmdLogger, w io.Writer) { l.SetWriter(w) },
+			expected: "\x1b[33mcareful\x1b[0m\n",
+		},
+		{
+			name:     "no colors when not a terminal",
+			setup:    func(l *CmdLogger, w io.Writer) { l.SetWriter(w) },
+			expected: "careful\n",
+		},
+		{
+			name: "use colors keeps colors when not a terminal",
+			setup: func(l *CmdLogger, w io.Writer) {
+				l.UseColors(true)
+				l.SetWriter(w)
+			},
+			expected: "\x1b[33mcareful\x1b[0m\n",
+		},
+		{
+			name:     "use colors false disables them on a terminal",
+			terminal: true,
+			setup: func(l *CmdLogger, w io.Writer) {
+				l.UseColors(false)
+				l.SetWriter(w)
+			},
+			expected: "careful\n",
+		},
+		{
+			name: "force colors overrides the detection",
+			setup: func(l *CmdLogger, w io.Writer) {
+				l.SetWriter(w)
+				l.ForceColors(true)
+			},
+			expected: "\x1b[33mcareful\x1b[0m\n",
+		},
+		{
+			name: "force colors false restores the detection",
+			setup: func(l *CmdLogger, w io.Writer) {
+				l.ForceColors(true)
+				l.SetWriter(w)
+				l.ForceColors(false)
+			},
+			expected: "careful\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalIsTerminal := isTerminal
+			defer func() { isTerminal = originalIsTerminal }()
+			isTerminal = func(io.Writer) bool { return tt.terminal }
+
+			var output bytes.Buffer
+			l := &CmdLogger{}
+			tt.setup(l, &output)
+
+			l.Warn("careful")
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestCmdLogger_NoColorsStripsHighlight(t *testing.T) {
+	var output bytes.Buffer
+	l := &CmdLogger{}
+	l.SetWriter(&output)
+
+	l.LogHighlight("Processing %s", Info, strcolor.Green, "item")
+	assert.Equal(t, "Processing item\n", output.String())
+}
+
+func TestCmdLogger_Inline(t *testing.T) {
+	tests := []struct {
+		name     string
+		noColors bool
+		log      func(l *CmdLogger)
+		expected string
+	}{
+		{
+			name: "inline keeps the line open",
+			log: func(l *CmdLogger) {
+				l.Inline("\rProgress %d%%", Info, 10)
+				l.Inline("\rProgress %d%%", Info, 100)
+			},
+			expected: "\x1b[0m\rProgress 10%\x1b[0m\x1b[0m\rProgress 100%\x1b[0m",
+		},
+		{
+			name: "newline finalizes the line",
+			log: func(l *CmdLogger) {
+				l.Inline("\rProgress %d%%", Warning, 50)
+				l.Newline()
+				l.Success("done")
+			},
+			expected: "\x1b[33m\rProgress 50%\x1b[0m\n\x1b[32mdone\x1b[0m\n",
+		},
+		{
+			name:     "inline without colors",
+			noColors: true,
+			log: func(l *CmdLogger) {
+				l.Inline("\rProgress %d%%", Error, 75)
+			},
+			expected: "\rProgress 75%",
+		},
+		{
+			name: "unknown level is dropped",
+			log: func(l *CmdLogger) {
+				l.Inline("ignored", Level(10))
+			},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			l := &CmdLogger{writer: &output, noColors: tt.noColors}
+
+			tt.log(l)
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestCmdLogger_AddWriter(t *testing.T) {
+	originalIsTerminal := isTerminal
+	defer func() { isTerminal = originalIsTerminal }()
+
+	var console, tail, plain bytes.Buffer
+	isTerminal = func(w io.Writer) bool { return w != &plain }
+
+	l := &CmdLogger{}
+	l.SetWriter(&console)
+	l.AddWriter(&tail)
+	l.AddWriter(&plain)
+
+	l.Warn("careful")
+	assert.Equal(t, "\x1b[33mcareful\x1b[0m\n", console.String())
+	assert.Equal(t, "\x1b[33mcareful\x1b[0m\n", tail.String())
+	assert.Equal(t, "careful\n", plain.String())
+
+	l.RemoveWriter(&tail)
+	l.Info("hello")
+	assert.Equal(t, "\x1b[33mcareful\x1b[0m\n", tail.String())
+	assert.Equal(t, "careful\nhello\n", plain.String())
+
+	// Colors set explicitly apply to every writer
+	l.UseColors(false)
+	l.Error("failed")
+	assert.True(t, strings.HasSuffix(console.String(), "\nfailed\n"))
+	assert.Equal(t, "careful\nhello\nfailed\n", plain.String())
+
+	l.Inline("progress", Info)
+	l.Newline()
+	assert.Equal(t, "careful\nhello\nfailed\nprogress\n", plain.String())
+}
+
+func TestCmdLogger_SetIconSeparator(t *testing.T) {
+	tests := []struct {
+		name      string
+		separator string
+		expected  string
+	}{
+		{name: "default single space", expected: string(IconInfo) + " hello\n"},
+		{name: "two spaces", separator: "  ", expected: string(IconInfo) + "  hello\n"},
+		{name: "tab", separator: "\t", expected: string(IconInfo) + "\thello\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			l := &CmdLogger{writer: &output, noColors: true}
+			l.UseIcons(true)
+			l.SetIconSeparator(tt.separator)
+
+			l.Info("hello")
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestCmdLogger_ShowLevelLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      func(l *CmdLogger)
+		expected string
+	}{
+		{
+			name:     "error label",
+			log:      func(l *CmdLogger) { l.Error("failed") },
+			expected: "ERROR: " + string(IconRevolvingLight) + " failed\n",
+		},
+		{
+			name:     "warn label",
+			log:      func(l *CmdLogger) { l.Warn("careful") },
+			expected: "WARN: " + string(IconWarning) + " careful\n",
+		},
+		{
+			name:     "label before the correlation id",
+			log:      func(l *CmdLogger) { l.printCorrelated("req-1", "hello", IconInfo, "info") },
+			expected: "INFO: [req-1] " + string(IconInfo) + " hello\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			l := &CmdLogger{writer: &output, noColors: true}
+			l.UseIcons(true)
+			l.UseCorrelationId(true)
+			l.ShowLevelLabel(true)
+
+			tt.log(l)
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+
+	t.Run("label after the timestamp", func(t *testing.T) {
+		var output bytes.Buffer
+		l := &CmdLogger{writer: &output, noColors: true}
+		l.UseTimestamp(true)
+		l.ShowLevelLabel(true)
+
+		l.Debug("details")
+		assert.Regexp(t, `^\S+ DEBUG: details\n$`, output.String())
+	})
+}
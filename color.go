@@ -1,6 +1,11 @@
 package log
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
 
 type ColorCode int
 
@@ -23,6 +28,39 @@ const (
 	BrightWhite
 )
 
+const (
+	// color256Flag marks a ColorCode holding a 256-color palette index
+	color256Flag ColorCode = 1 << 24
+	// trueColorFlag marks a ColorCode holding a 24-bit RGB color
+	trueColorFlag ColorCode = 1 << 25
+)
+
+// colorDepth is the number of colors a terminal can show
+type colorDepth int
+
+const (
+	depthBasic colorDepth = iota
+	depth256
+	depthTrueColor
+)
+
+// Color256 returns the ColorCode of the 256-color palette index, usable
+// anywhere a ColorCode is, such as LogHighlight or SetLevelColor
+func Color256(code int) ColorCode {
+	return color256Flag | ColorCode(clampColor(code))
+}
+
+// TrueColor returns the ColorCode of the 24-bit color, usable anywhere a
+// ColorCode is, such as LogHighlight or SetLevelColor
+func TrueColor(r, g, b int) ColorCode {
+	return trueColorFlag | ColorCode(clampColor(r)<<16|clampColor(g)<<8|clampColor(b))
+}
+
+// clampColor keeps a color component in the 0 to 255 range
+func clampColor(value int) int {
+	return max(0, min(255, value))
+}
+
 func GetColorString(colorCode ColorCode, words ...string) string {
 	var builder string
 	for _, m := range words {
@@ -32,5 +70,113 @@ func GetColorString(colorCode ColorCode, words ...string) string {
 		builder += m
 	}
 
-	return fmt.Sprintf("\033[%vm%v\033[0m", fmt.Sprint(colorCode), builder)
+	return fmt.Sprintf("%v%v\033[0m", colorSequence(colorCode), builder)
+}
+
+// GetColor256String colors the words with the 256-color palette index, on
+// terminals without 256 colors the nearest basic color is used
+func GetColor256String(code int, words ...string) string {
+	return GetColorString(Color256(code), words...)
+}
+
+// GetTrueColorString colors the words with the 24-bit color, on terminals
+// without truecolor the nearest 256 or basic color is used
+func GetTrueColorString(r, g, b int, words ...string) string {
+	return GetColorString(TrueColor(r, g, b), words...)
+}
+
+// colorSequence returns the ANSI sequence switching the terminal to the color,
+// extended colors fall back to what the terminal supports
+func colorSequence(colorCode ColorCode) string {
+	depth := terminalColorDepth()
+	switch {
+	case colorCode&trueColorFlag != 0:
+		r, g, b := int(colorCode>>16&0xff), int(colorCode>>8&0xff), int(colorCode&0xff)
+		switch depth {
+		case depthTrueColor:
+			return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+		case depth256:
+			return fmt.Sprintf("\033[38;5;%dm", rgbTo256(r, g, b))
+		}
+		return fmt.Sprintf("\033[%vm", fmt.Sprint(rgbToBasic(r, g, b)))
+	case colorCode&color256Flag != 0:
+		code := int(colorCode & 0xff)
+		if depth >= depth256 {
+			return fmt.Sprintf("\033[38;5;%dm", code)
+		}
+		r, g, b := color256ToRGB(code)
+		return fmt.Sprintf("\033[%vm", fmt.Sprint(rgbToBasic(r, g, b)))
+	}
+	return fmt.Sprintf("\033[%vm", fmt.Sprint(colorCode))
+}
+
+// terminalColorDepth reads the colors the terminal supports from COLORTERM
+// and TERM, tests replace it to take each fallback
+var terminalColorDepth = func() colorDepth {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return depthTrueColor
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return depth256
+	}
+	return depthBasic
+}
+
+// rgbTo256 returns the nearest index of the 6x6x6 color cube of the 256-color palette
+func rgbTo256(r, g, b int) int {
+	level := func(value int) int { return (value*5 + 127) / 255 }
+	return 16 + 36*level(r) + 6*level(g) + level(b)
+}
+
+// color256ToRGB returns the 24-bit color of the 256-color palette index
+func color256ToRGB(code int) (int, int, int) {
+	switch {
+	case code < 16:
+		// The system colors, the bright ones are the last eight
+		value := 128
+		if code >= 8 {
+			value = 255
+		}
+		return (code & 1) * value, (code >> 1 & 1) * value, (code >> 2 & 1) * value
+	case code < 232:
+		level := func(value int) int {
+			if value == 0 {
+				return 0
+			}
+			return 55 + value*40
+		}
+		code -= 16
+		return level(code / 36), level(code / 6 % 6), level(code % 6)
+	default:
+		gray := 8 + (code-232)*10
+		return gray, gray, gray
+	}
+}
+
+// rgbToBasic returns the nearest of the eight basic colors, bright when any
+// component is close to full intensity
+func rgbToBasic(r, g, b int) ColorCode {
+	index := 0
+	if r >= 128 {
+		index |= 1
+	}
+	if g >= 128 {
+		index |= 2
+	}
+	if b >= 128 {
+		index |= 4
+	}
+	if max(r, g, b) > 191 {
+		return BrightBlack + ColorCode(index)
+	}
+	return Black + ColorCode(index)
+}
+
+// ansiColorPattern matches the ANSI color sequences added by the loggers and LogHighlight
+var ansiColorPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// stripColors removes the ANSI color sequences from the text
+func stripColors(text string) string {
+	return ansiColorPattern.ReplaceAllString(text, "")
 }
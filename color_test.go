@@ -0,0 +1,53 @@
+package log
+
+import (
+	"testing"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetColorString_ExtendedColors(t *testing.T) {
+	originalDepth := terminalColorDepth
+	defer func() { terminalColorDepth = originalDepth }()
+
+	tests := []struct {
+		name     string
+		depth    colorDepth
+		color    func() string
+		expected string
+	}{
+		{name: "basic color", depth: depthTrueColor, color: func() string { return GetColorString(Red, "text") }, expected: "\033[31mtext\033[0m"},
+		{name: "256 colors", depth: depth256, color: func() string { return GetColor256String(208, "text") }, expected: "\033[38;5;208mtext\033[0m"},
+		{name: "256 colors on a basic terminal", depth: depthBasic, color: func() string { return GetColor256String(196, "text") }, expected: "\033[91mtext\033[0m"},
+		{name: "256 system color on a basic terminal", depth: depthBasic, color: func() string { return GetColor256String(2, "text") }, expected: "\033[32mtext\033[0m"},
+		{name: "truecolor", depth: depthTrueColor, color: func() string { return GetTrueColorString(255, 128, 0, "text") }, expected: "\033[38;2;255;128;0mtext\033[0m"},
+		{name: "truecolor on a 256 color terminal", depth: depth256, color: func() string { return GetTrueColorString(255, 128, 0, "text") }, expected: "\033[38;5;214mtext\033[0m"},
+		{name: "truecolor on a basic terminal", depth: depthBasic, color: func() string { return GetTrueColorString(0, 0, 139, "text") }, expected: "\033[34mtext\033[0m"},
+		{name: "truecolor out of range is clamped", depth: depthTrueColor, color: func() string { return GetTrueColorString(300, -5, 10, "text") }, expected: "\033[38;2;255;0;10mtext\033[0m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terminalColorDepth = func() colorDepth { return tt.depth }
+
+			assert.Equal(t, tt.expected, tt.color())
+			assert.Equal(t, "text", stripColors(tt.color()))
+		})
+	}
+}
+
+func TestCmdLogger_LogHighlightExtendedColor(t *testing.T) {
+	originalDepth := terminalColorDepth
+	defer func() { terminalColorDepth = originalDepth }()
+	terminalColorDepth = func() colorDepth { return depth256 }
+
+	var output syncBuffer
+	logger := CmdLogger{}.Init().(*CmdLogger)
+	logger.SetWriter(&output)
+	logger.ForceColors(true)
+
+	logger.LogHighlight("deployed %s", Info, strcolor.ColorCode(Color256(208)), "v1.2.0")
+
+	assert.Contains(t, output.String(), "deployed \033[38;5;208mv1.2.0\033[0m")
+}
@@ -0,0 +1,10 @@
+package log
+
+import "io"
+
+// colorTerminal reports whether the writer is a terminal able to show ANSI
+// colors, on Windows the console is switched to virtual terminal processing
+// first and a console that refuses it gets plain text
+func colorTerminal(w io.Writer) bool {
+	return isTerminal(w) && enableVirtualTerminal(w)
+}
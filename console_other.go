@@ -0,0 +1,11 @@
+//go:build !windows
+
+package log
+
+import "io"
+
+// enableVirtualTerminal is a no-op outside Windows, terminals handle the ANSI
+// sequences themselves
+func enableVirtualTerminal(w io.Writer) bool {
+	return true
+}
@@ -0,0 +1,22 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorTerminal(t *testing.T) {
+	originalIsTerminal := isTerminal
+	defer func() { isTerminal = originalIsTerminal }()
+
+	var output bytes.Buffer
+	isTerminal = func(io.Writer) bool { return false }
+	assert.False(t, colorTerminal(&output))
+
+	// Writers that are not a console need no virtual terminal step on any platform
+	isTerminal = func(io.Writer) bool { return true }
+	assert.True(t, colorTerminal(&output))
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package log
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// enableVirtualTerminalProcessing is the console mode flag making the Windows
+// console interpret ANSI sequences
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// enableVirtualTerminal switches the console behind the writer to virtual
+// terminal processing so the ANSI colors render instead of printing as text.
+// Writers that are not a console are left alone, false means the console
+// does not support it, as older Windows versions do not.
+func enableVirtualTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return true
+	}
+
+	handle := syscall.Handle(file.Fd())
+	var mode uint32
+	if err := syscall.GetConsoleMode(handle, &mode); err != nil {
+		// Not a console, such as a redirected handle
+		return true
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+
+	result, _, _ := procSetConsoleMode.Call(uintptr(handle), uintptr(mode|enableVirtualTerminalProcessing))
+	return result != 0
+}
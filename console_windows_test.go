@@ -0,0 +1,19 @@
+//go:build windows
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableVirtualTerminal_NotAConsole(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	assert.NoError(t, err)
+	defer file.Close()
+
+	assert.True(t, enableVirtualTerminal(file))
+}
@@ -3,9 +3,15 @@ package log
 import "github.com/fatih/color"
 
 const (
-	LOG_LEVEL string = "LOG_LEVEL"
+	LOG_LEVEL      string = "LOG_LEVEL"
+	LOG_FORMAT     string = "LOG_FORMAT"
+	CORRELATION_ID string = "CORRELATION_ID"
 )
 
+// taskCompleteMarker is appended by the text loggers to the message that
+// completes a task, see LoggerService.TaskSuccess
+const taskCompleteMarker = " [done]"
+
 // Logger Ansi Colors
 const (
 	SuccessColor  = color.FgGreen
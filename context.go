@@ -0,0 +1,436 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// contextKey is the type of the keys this package stores in a context
+type contextKey string
+
+// CorrelationIdKey is the context key holding a request scoped correlation id.
+// When present it takes precedence over the service active correlation id and
+// the CORRELATION_ID environment variable.
+//
+// Example:
+//
+//	ctx := context.WithValue(r.Context(), log.CorrelationIdKey, "req-123")
+//	service.InfoCtx(ctx, "Processing request")
+//	// Output: [req-123] Processing request
+const CorrelationIdKey contextKey = "correlation_id"
+
+// ContextWithCorrelationId returns a copy of the context carrying the correlation
+// id used by the *Ctx logging methods. The precedence of the correlation id is,
+// from highest to lowest, the id in the context, the service active id set with
+// RotateCorrelation and the CORRELATION_ID environment variable.
+//
+// Example:
+//
+//	ctx := log.ContextWithCorrelationId(r.Context(), "req-123")
+//	service.InfoCtx(ctx, "Processing request")
+//	// Output: [req-123] Processing request
+func ContextWithCorrelationId(ctx context.Context, id string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, CorrelationIdKey, id)
+}
+
+// CorrelationIdFromContext returns the correlation id stored in the context, or
+// an empty string when there is none.
+//
+// Example:
+//
+//	ctx := log.ContextWithCorrelationId(context.Background(), "req-123")
+//	fmt.Println(log.CorrelationIdFromContext(ctx))
+//	// Output: req-123
+func CorrelationIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	correlationId, _ := ctx.Value(CorrelationIdKey).(string)
+	return correlationId
+}
+
+// fieldsContextKey is the context key holding the fields of a LogEntry
+const fieldsContextKey contextKey = "fields"
+
+// fieldsFromContext returns the fields stored in the context by a LogEntry
+func fieldsFromContext(ctx context.Context) map[string]any {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(fieldsContextKey).(map[string]any)
+	return fields
+}
+
+// highlightContextKey is the context key holding the color the words of a
+// message are highlighted with in the text loggers, see SlogHandler
+const highlightContextKey contextKey = "highlight"
+
+// highlightWords returns the words highlighted with the color stored in the
+// context, or the words as they are when there is none
+func highlightWords(ctx context.Context, words []interface{}) []interface{} {
+	if ctx == nil {
+		return words
+	}
+
+	color, ok := ctx.Value(highlightContextKey).(ColorCode)
+	if !ok {
+		return words
+	}
+
+	highlighted := make([]interface{}, len(words))
+	for i, word := range words {
+		highlighted[i] = GetColorString(color, fmt.Sprintf("%v", word))
+	}
+	return highlighted
+}
+
+// taskContextKey is the context key marking a task message, it holds whether
+// the message completes the task, see TaskSuccess
+const taskContextKey contextKey = "task"
+
+// printTask sends a task message to a logger with task support using the task
+// method matching the level
+func printTask(logger taskLogger, level string, isComplete bool, format string, words ...interface{}) {
+	switch level {
+	case "success":
+		logger.TaskSuccess(format, isComplete, words...)
+	case "warn":
+		logger.TaskWarn(format, words...)
+	default:
+		logger.TaskError(format, isComplete, words...)
+	}
+}
+
+// messageFields returns the service build info fields merged with the fields
+// stored in the context, the context fields win on conflicting keys
+func (l *LoggerService) messageFields(ctx context.Context) map[string]any {
+	fields := fieldsFromContext(ctx)
+	if len(l.buildInfo) == 0 {
+		return fields
+	}
+
+	merged := make(map[string]any, len(l.buildInfo)+len(fields))
+	for key, value := range l.buildInfo {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return merged
+}
+
+// correlationIdFromEnv returns the correlation id set in the environment
+// variable, CORRELATION_ID when name is empty
+func correlationIdFromEnv(name string) string {
+	if name == "" {
+		name = CORRELATION_ID
+	}
+	return os.Getenv(name)
+}
+
+// resolveCorrelationId returns the correlation id for a message, in order of
+// precedence the id stored in the context, the active id set with
+// RotateCorrelation, the CORRELATION_ID environment variable, or the one set
+// with SetCorrelationEnv, and finally the
+// fallback set with WithCorrelationFallback
+func (l *LoggerService) resolveCorrelationId(ctx context.Context) string {
+	if correlationId := CorrelationIdFromContext(ctx); correlationId != "" {
+		return correlationId
+	}
+
+	l.correlationMutex.RLock()
+	correlationId := l.correlationId
+	fallback := l.correlationFallback
+	l.correlationMutex.RUnlock()
+	if correlationId != "" {
+		return correlationId
+	}
+
+	if correlationId := correlationIdFromEnv(l.correlationEnv); correlationId != "" {
+		return correlationId
+	}
+	return fallback
+}
+
+// RotateCorrelation replaces the active correlation id of the service, used by
+// every message that does not carry an id in its context, and logs a single
+// line linking the previous id to the new one for traceability.
+//
+// Example:
+//
+//	service := log.New().WithCorrelationId()
+//	service.RotateCorrelation("attempt-1")
+//	// retrying the operation
+//	service.RotateCorrelation("attempt-2")
+//	// Output: [attempt-2] correlation changed from attempt-1 to attempt-2
+func (l *LoggerService) RotateCorrelation(newId string) {
+	previousId := l.resolveCorrelationId(context.Background())
+
+	l.correlationMutex.Lock()
+	l.correlationId = newId
+	l.correlationMutex.Unlock()
+
+	if previousId == "" {
+		l.Info("correlation set to %s", newId)
+		return
+	}
+
+	l.Info("correlation changed from %s to %s", previousId, newId)
+}
+
+// logCtx sends a message to every logger using the correlation id and fields
+// resolved from the context. Structured loggers receive the fields as they are,
+// the others get them appended to the format and loggers that cannot receive
+// the correlation id fall back to their own method
+func (l *LoggerService) logCtx(ctx context.Context, icon LoggerIcon, level string, fallback func(Logger, string, ...interface{}), format string, words ...interface{}) {
+	if l.isSilenced(ctx) {
+		return
+	}
+	if !l.passesFilters(levelFromName(level), format, words) {
+		return
+	}
+
+	l.countMessage(level)
+	format = l.messagePrefix() + l.callerPrefix() + format
+	if l.dedup != nil {
+		last := func(suppressed int) {
+			l.dispatch(ctx, icon, level, fallback, messageMeta{repeat: suppressed}, fmt.Sprintf("%s (repeated %d times)", format, suppressed), words...)
+		}
+		key := dedupKey(level, format, words...)
+		if l.dedupFrames > 0 && level == "error" {
+			key = stackDedupKey(level, format, captureStack(l.stackFilter), l.dedupFrames)
+		}
+		if !l.dedup.allow(key, last) {
+			return
+		}
+	}
+
+	// Only the suppression line and the message logged after it are marked as
+	// sampled, messages with nothing suppressed before them are left as they are
+	meta := messageMeta{}
+	if l.sampler != nil {
+		pending := func(suppressed int) {
+			l.dispatch(ctx, icon, level, fallback, messageMeta{sampled: true}, "... %d similar messages suppressed", suppressed)
+		}
+		allowed, suppressed := l.sampler.allow(format, pending)
+		if suppressed > 0 {
+			meta.sampled = true
+			pending(suppressed)
+		}
+		if !allowed {
+			return
+		}
+	}
+
+	l.dispatch(ctx, icon, level, fallback, meta, format, words...)
+}
+
+// passesFilters reports whether every filter added with AddFilter accepts the
+// formatted message
+func (l *LoggerService) passesFilters(level Level, format string, words []interface{}) bool {
+	if len(l.filters) == 0 {
+		return true
+	}
+
+	message := formatMessage(format, words...)
+	for _, filter := range l.filters {
+		if !filter(level, message) {
+			return false
+		}
+	}
+	return true
+}
+
+// dispatch sends a message to every logger, see logCtx, the meta gets the
+// service source and the message fields. Only the text loggers get the words
+// highlighted when the context holds a highlight color and task messages go to
+// the task methods of the loggers supporting them
+func (l *LoggerService) dispatch(ctx context.Context, icon LoggerIcon, level string, fallback func(Logger, string, ...interface{}), meta messageMeta, format string, words ...interface{}) {
+	correlationId := l.resolveCorrelationId(ctx)
+	meta.source = l.source
+	meta.fields = l.messageFields(ctx)
+	textFormat := appendFields(format, meta.fields)
+	textWords := highlightWords(ctx, words)
+	isComplete, isTask := ctx.Value(taskContextKey).(bool)
+	for _, logger := range l.loggersFor(ctx) {
+		if tl, ok := logger.(taskLogger); ok && isTask {
+			printTask(tl, level, isComplete, textFormat, textWords...)
+		} else if sl, ok := logger.(structuredLogger); ok {
+			sl.printStructured(correlationId, meta, format, icon, level, words...)
+		} else if cl, ok := logger.(correlatedLogger); ok {
+			cl.printCorrelated(correlationId, textFormat, icon, level, textWords...)
+		} else {
+			fallback(logger, textFormat, textWords...)
+		}
+	}
+}
+
+// remember keeps a message below the service log level in the memory loggers,
+// which record every level, see AddMemoryLogger
+func (l *LoggerService) remember(ctx context.Context, icon LoggerIcon, level string, format string, words ...interface{}) {
+	for _, logger := range l.loggersFor(ctx) {
+		if ml, ok := logger.(*MemoryLogger); ok {
+			meta := messageMeta{source: l.source, fields: l.messageFields(ctx)}
+			ml.printStructured(l.resolveCorrelationId(ctx), meta, format, icon, level, words...)
+		}
+	}
+}
+
+// InfoCtx logs an informational message using the correlation id from the context.
+// Messages are only logged if the service's log level is Info or higher.
+//
+// Example:
+//
+//	service := log.New().WithCorrelationId()
+//	ctx := context.WithValue(context.Background(), log.CorrelationIdKey, "req-123")
+//	service.InfoCtx(ctx, "Server started on port %d", 8080)
+//	// Output: [req-123] Server started on port 8080
+func (l *LoggerService) InfoCtx(ctx context.Context, format string, words ...interface{}) {
+	if l.level() >= Info {
+		l.logCtx(ctx, IconInfo, "info", func(logger Logger, format string, words ...interface{}) { logger.Info(format, words...) }, format, words...)
+	} else {
+		l.remember(ctx, IconInfo, "info", format, words...)
+	}
+}
+
+// SuccessCtx logs a success message using the correlation id from the context.
+// Messages are only logged if the service's log level is Info or higher, or the
+// level set with SetSemanticLevel, unless the pseudo-level is set as always on
+// with WithAlwaysOn.
+func (l *LoggerService) SuccessCtx(ctx context.Context, format string, words ...interface{}) {
+	if l.pseudoLevelEnabled("success") {
+		l.logCtx(ctx, IconThumbsUp, "success", func(logger Logger, format string, words ...interface{}) { logger.Success(format, words...) }, format, words...)
+	} else {
+		l.remember(ctx, IconThumbsUp, "success", format, words...)
+	}
+}
+
+// WarnCtx logs a warning message using the correlation id from the context.
+// Messages are only logged if the service's log level is Warning or higher.
+func (l *LoggerService) WarnCtx(ctx context.Context, format string, words ...interface{}) {
+	if l.level() >= Warning {
+		l.logCtx(ctx, IconWarning, "warn", func(logger Logger, format string, words ...interface{}) { logger.Warn(format, words...) }, format, words...)
+	} else {
+		l.remember(ctx, IconWarning, "warn", format, words...)
+	}
+}
+
+// CommandCtx logs a command execution using the correlation id from the context.
+// Messages are only logged if the service's log level is Info or higher, or the
+// level set with SetSemanticLevel, unless the pseudo-level is set as always on
+// with WithAlwaysOn.
+func (l *LoggerService) CommandCtx(ctx context.Context, format string, words ...interface{}) {
+	if l.pseudoLevelEnabled("command") {
+		l.logCtx(ctx, IconWrench, "command", func(logger Logger, format string, words ...interface{}) { logger.Command(format, words...) }, format, words...)
+	} else {
+		l.remember(ctx, IconWrench, "command", format, words...)
+	}
+}
+
+// DisabledCtx logs a disabled feature message using the correlation id from the context.
+// Messages are only logged if the service's log level is Info or higher, or the
+// level set with SetSemanticLevel, unless the pseudo-level is set as always on
+// with WithAlwaysOn.
+func (l *LoggerService) DisabledCtx(ctx context.Context, format string, words ...interface{}) {
+	if l.pseudoLevelEnabled("disabled") {
+		l.logCtx(ctx, IconBlackSquare, "disabled", func(logger Logger, format string, words ...interface{}) { logger.Disabled(format, words...) }, format, words...)
+	} else {
+		l.remember(ctx, IconBlackSquare, "disabled", format, words...)
+	}
+}
+
+// NoticeCtx logs a notice message using the correlation id from the context.
+// Messages are only logged if the service's log level is Info or higher, or the
+// level set with SetSemanticLevel, unless the pseudo-level is set as always on
+// with WithAlwaysOn.
+func (l *LoggerService) NoticeCtx(ctx context.Context, format string, words ...interface{}) {
+	if l.pseudoLevelEnabled("notice") {
+		l.logCtx(ctx, IconFlag, "notice", func(logger Logger, format string, words ...interface{}) { logger.Notice(format, words...) }, format, words...)
+	} else {
+		l.remember(ctx, IconFlag, "notice", format, words...)
+	}
+}
+
+// DebugCtx logs a debug message using the correlation id from the context.
+// Messages are only logged if the service's log level is Debug or higher.
+func (l *LoggerService) DebugCtx(ctx context.Context, format string, words ...interface{}) {
+	if l.level() >= Debug {
+		l.logCtx(ctx, IconFire, "debug", func(logger Logger, format string, words ...interface{}) { logger.Debug(format, words...) }, format, words...)
+	} else {
+		l.remember(ctx, IconFire, "debug", format, words...)
+	}
+}
+
+// TraceCtx logs a trace message using the correlation id from the context.
+// Messages are only logged if the service's log level is Trace.
+func (l *LoggerService) TraceCtx(ctx context.Context, format string, words ...interface{}) {
+	if l.level() >= Trace {
+		l.logCtx(ctx, IconBulb, "trace", func(logger Logger, format string, words ...interface{}) { logger.Trace(format, words...) }, format, words...)
+	} else {
+		l.remember(ctx, IconBulb, "trace", format, words...)
+	}
+}
+
+// ErrorCtx logs an error message using the correlation id from the context.
+// Messages are only logged if the service's log level is Error or higher.
+func (l *LoggerService) ErrorCtx(ctx context.Context, format string, words ...interface{}) {
+	if l.level() >= Error {
+		format = format + escapeVerbs(l.stackTrace())
+		l.logCtx(ctx, IconRevolvingLight, "error", func(logger Logger, format string, words ...interface{}) { logger.Error(format, words...) }, format, words...)
+	}
+}
+
+// ExceptionCtx logs an error with additional context information using the
+// correlation id from the context.
+// Messages are only logged if the service's log level is Error or higher.
+//
+// Example:
+//
+//	err := errors.New("not found")
+//	service.ExceptionCtx(ctx, err, "Failed to load config from %s", "config.json")
+//	// Output: [req-123] Failed to load config from config.json, err not found
+func (l *LoggerService) ExceptionCtx(ctx context.Context, err error, format string, words ...interface{}) {
+	if l.level() >= Error {
+		message := format
+		if message == "" {
+			message = escapeVerbs(err.Error())
+		} else {
+			message = message + ", err " + escapeVerbs(err.Error())
+		}
+
+		// Loggers falling back to Exception get the chain and the stack through the error text
+		stackErr := err
+		if l.unwrapErrors {
+			var text string
+			message, text = unwrappedMessage(format, err)
+			stackErr = unwrappedError{err: err, text: text}
+		}
+		if stack := l.stackTrace(); stack != "" {
+			message = message + escapeVerbs(stack)
+			stackErr = fmt.Errorf("%w%s", stackErr, stack)
+		}
+		// The fallback gets the prefixes logCtx adds to the message, without the
+		// error text, and the fields after the error text like the other loggers
+		fallbackFormat := l.messagePrefix() + l.callerPrefix() + format
+		if fields := l.messageFields(ctx); len(fields) > 0 {
+			stackErr = unwrappedError{err: stackErr, text: stackErr.Error() + " " + fieldsText(fields)}
+		}
+		l.logCtx(ctx, IconRevolvingLight, "error", func(logger Logger, _ string, _ ...interface{}) { logger.Exception(stackErr, fallbackFormat, words...) }, message, words...)
+	}
+}
+
+// FatalCtx logs a fatal error message using the correlation id from the context.
+// Messages are only logged if the service's log level is Error or higher.
+func (l *LoggerService) FatalCtx(ctx context.Context, format string, words ...interface{}) {
+	if l.level() >= Error {
+		format = format + escapeVerbs(l.stackTrace())
+		l.logCtx(ctx, IconRevolvingLight, "error", func(logger Logger, format string, words ...interface{}) { logger.Fatal(format, words...) }, format, words...)
+	}
+}
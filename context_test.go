@@ -0,0 +1,289 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerService_ResolveCorrelationId(t *testing.T) {
+	os.Setenv("CORRELATION_ID", "env-id")
+	defer os.Unsetenv("CORRELATION_ID")
+
+	service := &LoggerService{}
+
+	t.Run("context value takes precedence", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), CorrelationIdKey, "ctx-id")
+		assert.Equal(t, "ctx-id", service.resolveCorrelationId(ctx))
+	})
+
+	t.Run("falls back to env when context has no id", func(t *testing.T) {
+		assert.Equal(t, "env-id", service.resolveCorrelationId(context.Background()))
+	})
+
+	t.Run("falls back to env on empty context id", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), CorrelationIdKey, "")
+		assert.Equal(t, "env-id", service.resolveCorrelationId(ctx))
+	})
+
+	t.Run("active id takes precedence over env", func(t *testing.T) {
+		service := &LoggerService{correlationId: "active-id"}
+		assert.Equal(t, "active-id", service.resolveCorrelationId(context.Background()))
+
+		ctx := context.WithValue(context.Background(), CorrelationIdKey, "ctx-id")
+		assert.Equal(t, "ctx-id", service.resolveCorrelationId(ctx))
+	})
+}
+
+func TestLoggerService_WithCorrelationFallback(t *testing.T) {
+	var output bytes.Buffer
+	service := &LoggerService{LogLevel: Info}
+	service.AddLogger(&CmdLogger{writer: &output, noColors: true})
+	service.WithCorrelationId()
+
+	service.Info("no fallback")
+	service.WithCorrelationFallback("-")
+	service.Info("with fallback")
+	service.InfoCtx(context.WithValue(context.Background(), CorrelationIdKey, "ctx-id"), "context wins")
+	t.Setenv("CORRELATION_ID", "env-id")
+	service.Info("env wins")
+	assert.Equal(t, "-", service.Clone().correlationFallback)
+
+	assert.Equal(t, "no fallback\n[-] with fallback\n[ctx-id] context wins\n[env-id] env wins\n", output.String())
+}
+
+func TestLoggerService_SetCorrelationEnv(t *testing.T) {
+	t.Setenv("CORRELATION_ID", "default-id")
+	t.Setenv("TRACE_ID", "trace-42")
+
+	var output bytes.Buffer
+	service := &LoggerService{LogLevel: Info}
+	service.AddLogger(&CmdLogger{writer: &output, noColors: true})
+	service.WithCorrelationId().SetCorrelationEnv("TRACE_ID")
+	var added bytes.Buffer
+	service.AddNDJSONLogger(&added)
+
+	service.Info("service path")
+	service.Log("logger path", Info)
+	assert.Equal(t, "[trace-42] service path\n[trace-42] logger path\n", output.String())
+	assert.Contains(t, added.String(), `"message":"[trace-42] service path"`)
+
+	service.SetCorrelationEnv("")
+	service.Info("default again")
+	assert.Contains(t, output.String(), "[default-id] default again\n")
+}
+
+func TestContextWithCorrelationId(t *testing.T) {
+	ctx := ContextWithCorrelationId(context.Background(), "req-456")
+	assert.Equal(t, "req-456", CorrelationIdFromContext(ctx))
+	assert.Equal(t, "", CorrelationIdFromContext(context.Background()))
+
+	var output bytes.Buffer
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{&CmdLogger{writer: &output}},
+	}
+	service.WithCorrelationId()
+
+	os.Setenv("CORRELATION_ID", "env-id")
+	defer os.Unsetenv("CORRELATION_ID")
+
+	service.InfoCtx(ctx, "handled")
+	assert.Equal(t, "\x1b[0m[req-456] handled\x1b[0m\n", output.String())
+}
+
+func TestLoggerService_InfoCtx(t *testing.T) {
+	var output bytes.Buffer
+	cmdLogger := &CmdLogger{writer: &output}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{cmdLogger},
+	}
+	service.WithCorrelationId()
+
+	ctx := context.WithValue(context.Background(), CorrelationIdKey, "req-123")
+	service.InfoCtx(ctx, "processing %s", "request")
+
+	assert.Equal(t, "\x1b[0m[req-123] processing request\x1b[0m\n", output.String())
+}
+
+func TestLoggerService_CtxMethods(t *testing.T) {
+	os.Setenv("CORRELATION_ID", "env-id")
+	defer os.Unsetenv("CORRELATION_ID")
+
+	ctx := context.WithValue(context.Background(), CorrelationIdKey, "ctx-id")
+	tests := []struct {
+		name    string
+		logFunc func(*LoggerService)
+		expect  string
+	}{
+		{
+			name:    "success",
+			logFunc: func(s *LoggerService) { s.SuccessCtx(ctx, "done") },
+			expect:  "\x1b[32m[ctx-id] done\x1b[0m\n",
+		},
+		{
+			name:    "warn",
+			logFunc: func(s *LoggerService) { s.WarnCtx(ctx, "careful") },
+			expect:  "\x1b[33m[ctx-id] careful\x1b[0m\n",
+		},
+		{
+			name:    "trace",
+			logFunc: func(s *LoggerService) { s.TraceCtx(ctx, "tracing") },
+			expect:  "\x1b[37m[ctx-id] tracing\x1b[0m\n",
+		},
+		{
+			name:    "exception",
+			logFunc: func(s *LoggerService) { s.ExceptionCtx(ctx, errors.New("boom"), "failed") },
+			expect:  "\x1b[31m[ctx-id] failed, err boom\x1b[0m\n",
+		},
+		{
+			name:    "exception with a percent in the error",
+			logFunc: func(s *LoggerService) { s.ExceptionCtx(ctx, errors.New("disk 100% full"), "failed") },
+			expect:  "\x1b[31m[ctx-id] failed, err disk 100% full\x1b[0m\n",
+		},
+		{
+			name:    "env fallback without context id",
+			logFunc: func(s *LoggerService) { s.ErrorCtx(context.Background(), "failed") },
+			expect:  "\x1b[31m[env-id] failed\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			service := &LoggerService{
+				LogLevel: Trace,
+				Loggers:  []Logger{&CmdLogger{writer: &output}},
+			}
+			service.WithCorrelationId()
+
+			tt.logFunc(service)
+
+			assert.Equal(t, tt.expect, output.String())
+		})
+	}
+}
+
+func TestLoggerService_CtxRespectsLevel(t *testing.T) {
+	var output bytes.Buffer
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{&CmdLogger{writer: &output}},
+	}
+
+	service.DebugCtx(context.Background(), "hidden")
+
+	assert.Empty(t, output.String())
+}
+
+func TestLoggerService_CtxFallsBackToLoggerMethods(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+
+	ctx := context.WithValue(context.Background(), CorrelationIdKey, "req-123")
+	service.NoticeCtx(ctx, "notice %s", "message")
+
+	assert.Equal(t, "notice message", mockLogger.LastPrintedMessage.Message)
+	assert.Equal(t, "notice", mockLogger.LastPrintedMessage.Level)
+	assert.Equal(t, string(IconFlag), mockLogger.LastPrintedMessage.Icon)
+}
+
+func TestLoggerService_ExceptionCtxFallbackKeepsPrefix(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+	service.prefix = "[auth]"
+
+	service.ExceptionCtx(context.Background(), errors.New("boom"), "login for %s failed", "bob")
+
+	assert.Equal(t, "[auth] login for bob failed, err boom", mockLogger.LastPrintedMessage.Message)
+}
+
+func TestLoggerService_CtxConcurrentCorrelationIds(t *testing.T) {
+	var output syncBuffer
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{&CmdLogger{writer: &output}},
+	}
+	service.WithCorrelationId()
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"req-1", "req-2", "req-3"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			ctx := context.WithValue(context.Background(), CorrelationIdKey, id)
+			service.InfoCtx(ctx, "handled %s", id)
+		}(id)
+	}
+	wg.Wait()
+
+	for _, id := range []string{"req-1", "req-2", "req-3"} {
+		assert.Contains(t, output.String(), "["+id+"] handled "+id)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLoggerService_RotateCorrelation(t *testing.T) {
+	var output bytes.Buffer
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{&CmdLogger{writer: &output}},
+	}
+	service.WithCorrelationId()
+
+	service.RotateCorrelation("attempt-1")
+	assert.Equal(t, "\x1b[0m[attempt-1] correlation set to attempt-1\x1b[0m\n", output.String())
+
+	output.Reset()
+	service.RotateCorrelation("attempt-2")
+	assert.Equal(t, "\x1b[0m[attempt-2] correlation changed from attempt-1 to attempt-2\x1b[0m\n", output.String())
+
+	output.Reset()
+	service.Warn("retrying")
+	service.Error("failed again")
+	assert.Equal(t, "\x1b[33m[attempt-2] retrying\x1b[0m\n\x1b[31m[attempt-2] failed again\x1b[0m\n", output.String())
+
+	t.Run("rotates from the env id", func(t *testing.T) {
+		os.Setenv("CORRELATION_ID", "env-id")
+		defer os.Unsetenv("CORRELATION_ID")
+
+		var output bytes.Buffer
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{&CmdLogger{writer: &output}},
+		}
+		service.WithCorrelationId()
+
+		service.RotateCorrelation("new-id")
+		assert.Equal(t, "\x1b[0m[new-id] correlation changed from env-id to new-id\x1b[0m\n", output.String())
+	})
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DedupMode selects how identical messages inside the dedup window are handled
+type DedupMode int
+
+const (
+	// DedupFirst logs the first message of a burst and drops the repeats
+	DedupFirst DedupMode = iota
+	// DedupFirstAndLast logs the first message of a burst immediately and, once
+	// the window elapses, the last one with the number of suppressed repeats
+	DedupFirstAndLast
+)
+
+// dedupBurst tracks the repeats of a message inside its window
+type dedupBurst struct {
+	suppressed int
+	last       func(suppressed int)
+	timer      *time.Timer
+}
+
+// deduplicator collapses identical messages logged within a window
+type deduplicator struct {
+	window time.Duration
+	mode   DedupMode
+	mutex  sync.Mutex
+	bursts map[string]*dedupBurst
+}
+
+func newDeduplicator(window time.Duration, mode DedupMode) *deduplicator {
+	return &deduplicator{
+		window: window,
+		mode:   mode,
+		bursts: make(map[string]*dedupBurst),
+	}
+}
+
+// allow reports whether the message should be logged now, last is kept to log
+// the final repeat of the burst when the mode asks for it
+func (d *deduplicator) allow(key string, last func(suppressed int)) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if burst, ok := d.bursts[key]; ok {
+		burst.suppressed++
+		burst.last = last
+		return false
+	}
+
+	d.bursts[key] = &dedupBurst{
+		timer: time.AfterFunc(d.window, func() { d.end(key) }),
+	}
+	return true
+}
+
+// end closes the burst of the key, logging its last repeat when required
+func (d *deduplicator) end(key string) {
+	d.mutex.Lock()
+	burst, ok := d.bursts[key]
+	if ok {
+		delete(d.bursts, key)
+	}
+	d.mutex.Unlock()
+
+	if ok && d.mode == DedupFirstAndLast && burst.suppressed > 0 {
+		burst.last(burst.suppressed)
+	}
+}
+
+// flush ends every open burst without waiting for their windows
+func (d *deduplicator) flush() {
+	d.mutex.Lock()
+	keys := make([]string, 0, len(d.bursts))
+	for key, burst := range d.bursts {
+		burst.timer.Stop()
+		keys = append(keys, key)
+	}
+	d.mutex.Unlock()
+
+	for _, key := range keys {
+		d.end(key)
+	}
+}
+
+// dedupKey identifies identical messages by level and formatted text
+func dedupKey(level string, format string, words ...interface{}) string {
+	if len(words) > 0 {
+		format = formatMessage(format, words...)
+	}
+	return level + "|" + format
+}
+
+// stackDedupKey identifies the same failure from the same place by level,
+// unformatted message and the function and line of the top frames, so repeats
+// whose arguments vary are still collapsed
+func stackDedupKey(level string, format string, frames []runtime.Frame, depth int) string {
+	if len(frames) > depth {
+		frames = frames[:depth]
+	}
+
+	locations := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		locations = append(locations, fmt.Sprintf("%s:%d", frame.Function, frame.Line))
+	}
+	return level + "|" + format + "|" + strings.Join(locations, ",")
+}
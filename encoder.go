@@ -0,0 +1,104 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Encoder renders a structured message as the bytes written by a WriterLogger,
+// including the trailing newline
+type Encoder interface {
+	Encode(msg LogMessage) ([]byte, error)
+}
+
+// TextEncoder renders messages as human readable lines, the timestamp when
+// set, the message and the fields as sorted key=value pairs
+//
+// Example:
+//
+//	2024-03-20T10:00:00Z request handled route=/login user_id=42
+type TextEncoder struct{}
+
+// Encode renders the message as a text line
+func (TextEncoder) Encode(msg LogMessage) ([]byte, error) {
+	var builder strings.Builder
+	if !msg.Timestamp.IsZero() {
+		builder.WriteString(msg.Timestamp.Format(time.RFC3339) + " ")
+	}
+	builder.WriteString(msg.Message)
+	if msg.Error != "" {
+		builder.WriteString(" error=" + fieldValue(msg.Error))
+	}
+	for _, key := range sortedKeys(msg.Fields) {
+		builder.WriteString(" " + key + "=" + fieldValue(msg.Fields[key]))
+	}
+	builder.WriteString("\n")
+	return []byte(builder.String()), nil
+}
+
+// JSONEncoder renders messages as one LogMessage JSON object per line, the
+// timestamp is left out when it is not set
+//
+// Example:
+//
+//	{"level":"info","message":"request handled",...,"fields":{"user_id":42}}
+type JSONEncoder struct{}
+
+// Encode renders the message as a JSON line
+func (JSONEncoder) Encode(msg LogMessage) ([]byte, error) {
+	record := msg.wire()
+	if msg.Timestamp.IsZero() {
+		record.Timestamp = ""
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// LogfmtEncoder renders messages as logfmt lines of key=value pairs
+//
+// Example:
+//
+//	time=2024-03-20T10:00:00Z level=info msg="request handled" user_id=42
+type LogfmtEncoder struct{}
+
+// Encode renders the message as a logfmt line
+func (LogfmtEncoder) Encode(msg LogMessage) ([]byte, error) {
+	pairs := make([]string, 0, len(msg.Fields)+5)
+	if !msg.Timestamp.IsZero() {
+		pairs = append(pairs, "time="+msg.Timestamp.Format(time.RFC3339))
+	}
+	pairs = append(pairs, "level="+msg.Level, "msg="+fieldValue(msg.Message))
+	if msg.Source != "" {
+		pairs = append(pairs, "logger="+fieldValue(msg.Source))
+	}
+	if msg.Error != "" {
+		pairs = append(pairs, "error="+fieldValue(msg.Error))
+	}
+	if msg.Sampled {
+		pairs = append(pairs, "sampled=true")
+	}
+	if msg.Repeat > 0 {
+		pairs = append(pairs, fmt.Sprintf("repeat=%d", msg.Repeat))
+	}
+	for _, key := range sortedKeys(msg.Fields) {
+		pairs = append(pairs, key+"="+fieldValue(msg.Fields[key]))
+	}
+	return []byte(strings.Join(pairs, " ") + "\n"), nil
+}
+
+// sortedKeys returns the keys of the fields in sorted order
+func sortedKeys(fields map[string]any) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
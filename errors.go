@@ -0,0 +1,96 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// unwrappedError keeps the original error in the chain of loggers falling
+// back to Exception while its text is the rendered chain
+type unwrappedError struct {
+	err  error
+	text string
+}
+
+func (e unwrappedError) Error() string {
+	return e.text
+}
+
+func (e unwrappedError) Unwrap() error {
+	return e.err
+}
+
+// WithUnwrappedErrors makes Exception walk the errors.Unwrap chain of the error
+// and log each layer on its own indented line instead of appending the error
+// text to the message. When a layer carries a stack, like the errors of
+// github.com/pkg/errors do, the deepest one is printed after the chain.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithUnwrappedErrors(true)
+//	err := fmt.Errorf("load config: %w", fmt.Errorf("open file: %w", os.ErrPermission))
+//	service.Exception(err, "Failed to start")
+//	// Output: Failed to start
+//	//   load config
+//	//   open file
+//	//   permission denied
+func (l *LoggerService) WithUnwrappedErrors(value bool) *LoggerService {
+	l.unwrapErrors = value
+	return l
+}
+
+// unwrappedMessage returns the Exception format string for the error chain and
+// the chain text for loggers appending it themselves. The chain starts with an
+// indented line, the first layer takes the place of the error text when there
+// is no message to indent it under.
+func unwrappedMessage(format string, err error) (string, string) {
+	chain := errorChain(err)
+	text := strings.TrimPrefix(chain, "\n  ")
+	if format == "" {
+		return escapeVerbs(text), text
+	}
+	return format + escapeVerbs(chain), text
+}
+
+// errorChain renders every layer of the error chain on its own indented line,
+// each layer without the text of the layers it wraps, followed by the deepest
+// stack found in the chain
+func errorChain(err error) string {
+	var builder strings.Builder
+	stack := ""
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		text := current.Error()
+		if inner := errors.Unwrap(current); inner != nil {
+			text = strings.TrimSuffix(strings.TrimSuffix(text, inner.Error()), ": ")
+		}
+		if text != "" {
+			builder.WriteString("\n  " + text)
+		}
+		if trace := errorStack(current); trace != "" {
+			stack = trace
+		}
+	}
+
+	return builder.String() + stack
+}
+
+// errorStack returns the stack carried by the error when it has a
+// StackTrace method, rendered with %+v like github.com/pkg/errors does
+func errorStack(err error) string {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ""
+	}
+
+	trace := strings.TrimRight(fmt.Sprintf("%+v", method.Call(nil)[0].Interface()), "\n")
+	if trace == "" {
+		return ""
+	}
+	if !strings.HasPrefix(trace, "\n") {
+		trace = "\n" + trace
+	}
+	return trace
+}
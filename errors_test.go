@@ -0,0 +1,132 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStack renders like the github.com/pkg/errors StackTrace with %+v
+type fakeStack []string
+
+func (s fakeStack) Format(f fmt.State, verb rune) {
+	for _, frame := range s {
+		fmt.Fprintf(f, "\n%s", frame)
+	}
+}
+
+// stackError is an error carrying a stack like the github.com/pkg/errors ones
+type stackError struct {
+	message string
+	stack   fakeStack
+}
+
+func (e stackError) Error() string {
+	return e.message
+}
+
+func (e stackError) StackTrace() fakeStack {
+	return e.stack
+}
+
+func TestErrorChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "single error",
+			err:      errors.New("boom"),
+			expected: "\n  boom",
+		},
+		{
+			name:     "wrapped errors",
+			err:      fmt.Errorf("load config: %w", fmt.Errorf("open file: %w", os.ErrPermission)),
+			expected: "\n  load config\n  open file\n  permission denied",
+		},
+		{
+			name:     "wrapper with its own text after the inner error",
+			err:      fmt.Errorf("%w (retried)", errors.New("timeout")),
+			expected: "\n  timeout (retried)\n  timeout",
+		},
+		{
+			name:     "stack of the deepest layer",
+			err:      fmt.Errorf("charge: %w", stackError{message: "declined", stack: fakeStack{"main.charge", "\t/app/payments.go:42"}}),
+			expected: "\n  charge\n  declined\nmain.charge\n\t/app/payments.go:42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, errorChain(tt.err))
+		})
+	}
+}
+
+func TestLoggerService_WithUnwrappedErrors(t *testing.T) {
+	err := fmt.Errorf("load config: %w", fmt.Errorf("open file: %w", os.ErrPermission))
+
+	tests := []struct {
+		name     string
+		unwrap   bool
+		format   string
+		expected string
+	}{
+		{
+			name:     "single line by default",
+			format:   "Failed to start",
+			expected: "Failed to start, err load config: open file: permission denied",
+		},
+		{
+			name:     "each layer on its own line",
+			unwrap:   true,
+			format:   "Failed to start",
+			expected: "Failed to start\n  load config\n  open file\n  permission denied",
+		},
+		{
+			name:     "first layer replaces an empty message",
+			unwrap:   true,
+			expected: "load config\n  open file\n  permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output, entryOutput bytes.Buffer
+			service := &LoggerService{
+				LogLevel: Info,
+				Loggers:  []Logger{&CmdLogger{writer: &output, noColors: true}},
+			}
+			assert.Same(t, service, service.WithUnwrappedErrors(tt.unwrap))
+
+			service.Exception(err, tt.format)
+			assert.Equal(t, tt.expected+"\n", output.String())
+
+			service.Loggers = []Logger{&CmdLogger{writer: &entryOutput, noColors: true}}
+			service.WithField("id", 1).Exception(err, tt.format)
+			assert.Equal(t, tt.expected+" id=1\n", entryOutput.String())
+		})
+	}
+}
+
+func TestLoggerService_WithUnwrappedErrorsAndStackTraces(t *testing.T) {
+	err := fmt.Errorf("load config: %w", os.ErrPermission)
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+	service.WithUnwrappedErrors(true).WithStackTraces(true)
+
+	service.Exception(err, "Failed to start")
+
+	message := mockLogger.LastPrintedMessage.Message
+	assert.True(t, strings.HasPrefix(message, "Failed to start, err load config\n  permission denied\n"), message)
+	assert.Contains(t, message, "TestLoggerService_WithUnwrappedErrorsAndStackTraces")
+}
@@ -1,24 +1,97 @@
 package log
 
 import (
+	"bufio"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	strcolor "github.com/cjlapao/common-go/strcolor"
 )
 
+const (
+	// dailyRotationLayout is the date suffix used for daily rotated files
+	dailyRotationLayout = "2006-01-02"
+	// bufferFlushInterval is how often a buffered file logger is flushed
+	bufferFlushInterval = time.Second
+	// bufferStatInterval is how many buffered writes happen between file Stat calls
+	bufferStatInterval = 100
+	// defaultMaxBackups is how many rotated files are kept, .01 to .09
+	defaultMaxBackups = 9
+	// defaultFileMode is the permission the log files are created with, before the umask
+	defaultFileMode os.FileMode = 0o666
+)
+
 // FileLogger Command Line Logger implementation
 type FileLogger struct {
 	useTimestamp      bool
 	userCorrelationId bool
 	useIcons          bool
+	correlationEnv    string
+	uptimeStart       time.Time
+	clock             func() time.Time
 	filename          string
 	enabled           bool
+	redirected        bool
+	closed            bool
+	compressRotated   bool
+	maxSize           int64
+	maxTotalSize      int64
+	maxBackups        int
+	fileMode          os.FileMode
+	fileSize          int64
+	writesSinceStat   int
+	batches           int
+	buffer            *bufio.Writer
+	stopFlush         chan struct{}
+	rotateDaily       bool
+	syncOnError       bool
+	lastWrite         time.Time
 	writer            io.Writer
+	redactors         []Redactor
+	writerMutex       *sync.Mutex
+	compressing       *sync.WaitGroup
+	options           []FileOption
+}
+
+// FileOption configures a file logger added with AddFileLogger
+type FileOption func(*FileLogger)
+
+// WithMaxSize sets the size in bytes the file can reach before it is rotated,
+// overriding the MAX_LOG_FILE_SIZE environment variable
+func WithMaxSize(bytes int64) FileOption {
+	return func(l *FileLogger) {
+		l.SetMaxSize(bytes)
+	}
+}
+
+// WithMaxBackups sets how many rotated files are kept, 9 by default
+func WithMaxBackups(count int) FileOption {
+	return func(l *FileLogger) {
+		l.SetMaxBackups(count)
+	}
+}
+
+// WithFileMode sets the permission the log file and its rotations are created
+// with, 0666 before the umask by default
+func WithFileMode(mode os.FileMode) FileOption {
+	return func(l *FileLogger) {
+		l.fileMode = mode
+	}
+}
+
+// WithCompression gzips each rotated file in the background, see CompressRotated
+func WithCompression() FileOption {
+	return func(l *FileLogger) {
+		l.CompressRotated(true)
+	}
 }
 
 func (l FileLogger) Init() Logger {
@@ -27,9 +100,17 @@ func (l FileLogger) Init() Logger {
 		userCorrelationId: false,
 		useIcons:          false,
 		filename:          l.filename,
+		maxSize:           maxSizeFromEnv(),
+		maxBackups:        defaultMaxBackups,
+		fileMode:          defaultFileMode,
+		writerMutex:       &sync.Mutex{},
+		compressing:       &sync.WaitGroup{},
+	}
+	for _, opt := range l.options {
+		opt(logger)
 	}
 	if l.filename != "" {
-		file, err := os.OpenFile(l.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
+		file, err := os.OpenFile(l.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, logger.fileMode)
 		if err != nil {
 			panic(err)
 		}
@@ -42,6 +123,11 @@ func (l FileLogger) Init() Logger {
 	return logger
 }
 
+// registrationKey lets loggers writing to different files be registered together
+func (l *FileLogger) registrationKey() string {
+	return l.filename
+}
+
 func (l *FileLogger) IsTimestampEnabled() bool {
 	return l.useTimestamp
 }
@@ -50,6 +136,18 @@ func (l *FileLogger) UseTimestamp(value bool) {
 	l.useTimestamp = value
 }
 
+// UseUptimeTimestamp renders timestamps as the elapsed time since startedAt,
+// a zero startedAt restores the wall-clock timestamps
+func (l *FileLogger) UseUptimeTimestamp(startedAt time.Time) {
+	l.uptimeStart = startedAt
+}
+
+// SetClock sets the clock the timestamps are read from, so tests can freeze
+// the time, nil restores the wall clock
+func (l *FileLogger) SetClock(clock func() time.Time) {
+	l.clock = clock
+}
+
 func (l *FileLogger) UseCorrelationId(value bool) {
 	l.userCorrelationId = value
 }
@@ -58,19 +156,109 @@ func (l *FileLogger) UseIcons(value bool) {
 	l.useIcons = value
 }
 
+// SetCorrelationEnv sets the environment variable the correlation id is read
+// from, CORRELATION_ID when empty
+func (l *FileLogger) SetCorrelationEnv(name string) {
+	l.correlationEnv = name
+}
+
+// SetRedactors sets the redactors run on every message before it is written
+func (l *FileLogger) SetRedactors(redactors []Redactor) {
+	l.redactors = redactors
+}
+
+// CompressRotated gzips each rotated file in the background, the plain
+// rotated file is removed once its .gz copy is written
+func (l *FileLogger) CompressRotated(value bool) {
+	l.compressRotated = value
+}
+
+// RotateDaily rolls the file over to a date suffixed file, like app.log.2024-01-01,
+// on the first write of a new day, size rotation still applies and the backup
+// count and max total size also cover the dated files
+func (l *FileLogger) RotateDaily(value bool) {
+	l.rotateDaily = value
+}
+
+// SetWriter writes the messages to w instead of the file, which is closed.
+// Rotation stops as the logger no longer owns its destination and Close
+// leaves w open.
+func (l *FileLogger) SetWriter(w io.Writer) {
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	if l.closed {
+		return
+	}
+	if l.buffer != nil {
+		l.buffer.Flush()
+		l.buffer.Reset(w)
+	}
+	if file, ok := l.writer.(*os.File); ok && l.enabled && !l.redirected {
+		file.Close()
+	}
+
+	l.writer = w
+	l.enabled = true
+	l.redirected = true
+}
+
+// SyncOnError flushes the buffer and syncs the file to disk after every error
+// level message, so errors are durable at once while the other levels are left
+// to the buffer and the OS
+func (l *FileLogger) SyncOnError(value bool) {
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	l.syncOnError = value
+}
+
+// SetMaxSize sets the size in bytes the file can reach before it is rotated,
+// overriding the MAX_LOG_FILE_SIZE environment variable read on Init
+func (l *FileLogger) SetMaxSize(bytes int64) {
+	l.maxSize = bytes
+}
+
+// SetMaxBackups sets how many rotated files are kept, .01 being the newest,
+// and how many daily files are kept, counts below one keep the default of 9
+func (l *FileLogger) SetMaxBackups(count int) {
+	if count < 1 {
+		count = defaultMaxBackups
+	}
+	l.maxBackups = count
+}
+
+// SetMaxTotalSize caps the bytes used by the file and all its rotations, the
+// oldest rotations are deleted on rotation until the total fits, zero disables it
+func (l *FileLogger) SetMaxTotalSize(bytes int64) {
+	l.maxTotalSize = bytes
+}
+
+// WouldLog reports whether a message at the level would be written to the file
+func (l *FileLogger) WouldLog(level Level) bool {
+	if !l.enabled {
+		return false
+	}
+
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	return !l.closed
+}
+
 // Log Log information message
 func (l *FileLogger) Log(format string, level Level, words ...interface{}) {
 	switch level {
 	case 0:
-		l.printMessage(format, "", "error", false, false, words...)
+		l.printMessage(format, "", "error", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 1:
-		l.printMessage(format, "", "warn", false, false, words...)
+		l.printMessage(format, "", "warn", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 2:
-		l.printMessage(format, "", "info", false, false, words...)
+		l.printMessage(format, "", "info", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 3:
-		l.printMessage(format, "", "debug", false, false, words...)
+		l.printMessage(format, "", "debug", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 4:
-		l.printMessage(format, "", "trace", false, false, words...)
+		l.printMessage(format, "", "trace", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	}
 }
 
@@ -78,15 +266,15 @@ func (l *FileLogger) Log(format string, level Level, words ...interface{}) {
 func (l *FileLogger) LogIcon(icon LoggerIcon, format string, level Level, words ...interface{}) {
 	switch level {
 	case 0:
-		l.printMessage(format, icon, "error", false, false, words...)
+		l.printMessage(format, icon, "error", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 1:
-		l.printMessage(format, icon, "warn", false, false, words...)
+		l.printMessage(format, icon, "warn", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 2:
-		l.printMessage(format, icon, "info", false, false, words...)
+		l.printMessage(format, icon, "info", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 3:
-		l.printMessage(format, icon, "debug", false, false, words...)
+		l.printMessage(format, icon, "debug", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 4:
-		l.printMessage(format, icon, "trace", false, false, words...)
+		l.printMessage(format, icon, "trace", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	}
 }
 
@@ -100,71 +288,71 @@ func (l *FileLogger) LogHighlight(format string, level Level, highlightColor str
 
 	switch level {
 	case 0:
-		l.printMessage(format, "", "error", false, false, words...)
+		l.printMessage(format, "", "error", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 1:
-		l.printMessage(format, "", "warn", false, false, words...)
+		l.printMessage(format, "", "warn", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 2:
-		l.printMessage(format, "", "info", false, false, words...)
+		l.printMessage(format, "", "info", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 3:
-		l.printMessage(format, "", "debug", false, false, words...)
+		l.printMessage(format, "", "debug", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	case 4:
-		l.printMessage(format, "", "trace", false, false, words...)
+		l.printMessage(format, "", "trace", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 	}
 }
 
 // Info log information message
 func (l *FileLogger) Info(format string, words ...interface{}) {
-	l.printMessage(format, IconInfo, "info", false, false, words...)
+	l.printMessage(format, IconInfo, "info", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Success log message
 func (l *FileLogger) Success(format string, words ...interface{}) {
-	l.printMessage(format, IconThumbsUp, "success", false, false, words...)
+	l.printMessage(format, IconThumbsUp, "success", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // TaskSuccess log message
 func (l *FileLogger) TaskSuccess(format string, isComplete bool, words ...interface{}) {
-	l.printMessage(format, "", "success", true, isComplete, words...)
+	l.printMessage(format, "", "success", true, isComplete, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Warn log message
 func (l *FileLogger) Warn(format string, words ...interface{}) {
-	l.printMessage(format, IconWarning, "warn", false, false, words...)
+	l.printMessage(format, IconWarning, "warn", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // TaskWarn log message
 func (l *FileLogger) TaskWarn(format string, words ...interface{}) {
-	l.printMessage(format, "", "warn", true, false, words...)
+	l.printMessage(format, "", "warn", true, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Command log message
 func (l *FileLogger) Command(format string, words ...interface{}) {
-	l.printMessage(format, IconWrench, "command", false, false, words...)
+	l.printMessage(format, IconWrench, "command", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Disabled log message
 func (l *FileLogger) Disabled(format string, words ...interface{}) {
-	l.printMessage(format, IconBlackSquare, "disabled", false, false, words...)
+	l.printMessage(format, IconBlackSquare, "disabled", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Notice log message
 func (l *FileLogger) Notice(format string, words ...interface{}) {
-	l.printMessage(format, IconFlag, "notice", false, false, words...)
+	l.printMessage(format, IconFlag, "notice", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Debug log message
 func (l *FileLogger) Debug(format string, words ...interface{}) {
-	l.printMessage(format, IconFire, "debug", false, false, words...)
+	l.printMessage(format, IconFire, "debug", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Trace log message
 func (l *FileLogger) Trace(format string, words ...interface{}) {
-	l.printMessage(format, IconBulb, "trace", false, false, words...)
+	l.printMessage(format, IconBulb, "trace", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Error log message
 func (l *FileLogger) Error(format string, words ...interface{}) {
-	l.printMessage(format, IconRevolvingLight, "error", false, false, words...)
+	l.printMessage(format, IconRevolvingLight, "error", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Error log message
@@ -174,53 +362,59 @@ func (l *FileLogger) Exception(err error, format string, words ...interface{}) {
 	} else {
 		format = format + ", err " + err.Error()
 	}
-	l.printMessage(format, IconRevolvingLight, "error", false, false, words...)
+	l.printMessage(format, IconRevolvingLight, "error", false, false, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // LogError log message
 func (l *FileLogger) LogError(message error) {
 	if message != nil {
-		l.printMessage(message.Error(), IconRevolvingLight, "error", false, false)
+		l.printMessage(message.Error(), IconRevolvingLight, "error", false, false, correlationIdFromEnv(l.correlationEnv))
 	}
 }
 
 // TaskError log message
 func (l *FileLogger) TaskError(format string, isComplete bool, words ...interface{}) {
-	l.printMessage(format, "", "error", true, isComplete, l.useTimestamp)
+	l.printMessage(format, "", "error", true, isComplete, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // Fatal log message
 func (l *FileLogger) Fatal(format string, words ...interface{}) {
-	l.printMessage(format, IconRevolvingLight, "error", false, true, words...)
+	l.printMessage(format, IconRevolvingLight, "error", false, true, correlationIdFromEnv(l.correlationEnv), words...)
 }
 
 // FatalError log message
 func (l *FileLogger) FatalError(e error, format string, words ...interface{}) {
 	l.Error(format, words...)
+	l.Flush()
 	if e != nil {
 		panic(e)
 	}
 }
 
+// printCorrelated prints a message using a correlation id already resolved by the caller
+func (l *FileLogger) printCorrelated(correlationId string, format string, icon LoggerIcon, level string, words ...interface{}) {
+	l.printMessage(format, icon, level, false, false, correlationId, words...)
+}
+
 // printMessage Prints a message in the system
-func (l *FileLogger) printMessage(format string, icon LoggerIcon, level string, isTask bool, isComplete bool, words ...interface{}) {
+func (l *FileLogger) printMessage(format string, icon LoggerIcon, level string, isTask bool, isComplete bool, correlationId string, words ...interface{}) {
 	if !l.enabled {
 		return
 	}
 
+	if isTask {
+		format, words = taskMessage(format, isComplete, words)
+	}
 	if !strings.HasSuffix(format, "\n") {
 		format = format + "\n"
 	}
 
-	if l.userCorrelationId {
-		correlationId := os.Getenv("CORRELATION_ID")
-		if correlationId != "" {
-			format = "[" + correlationId + "] " + "[" + strings.ToUpper(level) + "]" + format
-		}
+	if l.userCorrelationId && correlationId != "" {
+		format = "[" + correlationId + "] " + "[" + strings.ToUpper(level) + "]" + format
 	}
 
 	if l.useTimestamp {
-		format = fmt.Sprintf("%s %s", time.Now().Format(time.RFC3339), format)
+		format = fmt.Sprintf("%s %s", formatTimestamp(clockNow(l.clock), l.uptimeStart), format)
 	}
 
 	formattedWords := make([]interface{}, len(words))
@@ -230,65 +424,453 @@ func (l *FileLogger) printMessage(format string, icon LoggerIcon, level string,
 		}
 	}
 
-	l.rotateLogFile()
-	l.writer.Write([]byte(fmt.Sprintf(format, formattedWords...)))
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	// Writing after Close is a no-op instead of a write to a closed file
+	if l.closed {
+		return
+	}
+
+	// Inside a LogBatch the rotation was checked when the batch started
+	if l.batches == 0 {
+		l.rotateLogFile()
+	}
+	message := []byte(redact(l.redactors, formatMessage(format, formattedWords...)))
+	if l.buffer != nil {
+		n, _ := l.buffer.Write(message)
+		l.fileSize += int64(n)
+		l.writesSinceStat++
+	} else {
+		l.writer.Write(message)
+	}
+
+	if l.syncOnError && level == "error" {
+		l.sync()
+	}
+}
+
+// beginBatch checks the rotation once for the messages of a LogBatch, they are
+// written without checking it again until endBatch
+func (l *FileLogger) beginBatch() {
+	if !l.enabled {
+		return
+	}
+
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	if !l.closed {
+		l.rotateLogFile()
+	}
+	l.batches++
+}
+
+// endBatch checks the rotation again before every message once the last
+// running LogBatch ends
+func (l *FileLogger) endBatch() {
+	if !l.enabled {
+		return
+	}
+
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	l.batches--
 }
 
+// UseBuffer buffers writes in memory up to size bytes, the buffer is flushed
+// when full, every second and on Close, a size of zero or less disables it.
+// The periodic flush does not sync the file to disk, only Flush does.
+func (l *FileLogger) UseBuffer(size int) {
+	if !l.enabled {
+		return
+	}
+
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	if l.buffer != nil {
+		l.buffer.Flush()
+		l.buffer = nil
+		close(l.stopFlush)
+		l.stopFlush = nil
+	}
+	if size <= 0 || l.closed {
+		return
+	}
+
+	l.buffer = bufio.NewWriterSize(l.writer, size)
+	// Force a Stat on the next write to pick up the current file size
+	l.writesSinceStat = bufferStatInterval
+	l.stopFlush = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(bufferFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.flushBuffer()
+			case <-stop:
+				return
+			}
+		}
+	}(l.stopFlush)
+}
+
+// flushBuffer writes the buffered messages to the file without syncing it to
+// disk, used by the buffer ticker so buffering never adds a sync per interval
+func (l *FileLogger) flushBuffer() {
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	if l.closed || l.buffer == nil {
+		return
+	}
+	l.buffer.Flush()
+}
+
+// Flush writes any buffered messages to the file and syncs the file to disk
+func (l *FileLogger) Flush() error {
+	if !l.enabled {
+		return nil
+	}
+
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	return l.sync()
+}
+
+// sync flushes the buffer and commits the file to disk, it must be called
+// with the writer mutex held
+func (l *FileLogger) sync() error {
+	if l.buffer != nil {
+		if err := l.buffer.Flush(); err != nil {
+			return err
+		}
+	}
+
+	file, ok := l.writer.(*os.File)
+	if !ok {
+		return nil
+	}
+	return file.Sync()
+}
+
+// Close flushes and closes the file, messages logged afterwards are dropped
 func (l *FileLogger) Close() {
 	if l.enabled {
+		l.writerMutex.Lock()
+		defer l.writerMutex.Unlock()
+
+		if l.closed {
+			return
+		}
+		l.closed = true
+
+		if l.buffer != nil {
+			l.buffer.Flush()
+			l.buffer = nil
+			close(l.stopFlush)
+			l.stopFlush = nil
+		}
+
 		file, ok := l.writer.(*os.File)
-		if ok {
+		if ok && !l.redirected {
 			file.Close()
 		}
+
+		// Wait for any rotated file still being compressed
+		l.compressing.Wait()
+	}
+}
+
+// maxSizeFromEnv returns the MAX_LOG_FILE_SIZE environment variable, or 5MB
+// when it is not set or invalid
+func maxSizeFromEnv() int64 {
+	maxSize := int64(1024 * 1024 * 5) // Default to 5MB if not set
+	if maxSizeStr := os.Getenv("MAX_LOG_FILE_SIZE"); maxSizeStr != "" {
+		if parsedSize, err := strconv.ParseInt(maxSizeStr, 10, 64); err == nil {
+			maxSize = parsedSize
+		}
 	}
+	return maxSize
 }
 
+// rotateLogFile must be called with the writer mutex held
 func (l *FileLogger) rotateLogFile() {
-	if l.enabled {
+	if l.enabled && !l.redirected {
 		file, ok := l.writer.(*os.File)
 		if ok {
-			fileInfo, err := file.Stat()
-			if err != nil {
+			// Roll the file over once the calendar day changes since the last write
+			now := clockNow(l.clock)
+			lastWrite := l.lastWrite
+			l.lastWrite = now
+			if l.rotateDaily && !lastWrite.IsZero() && lastWrite.Format(dailyRotationLayout) != now.Format(dailyRotationLayout) {
+				// A previous rotation may still be compressing and pruning its
+				// files, let it finish before picking the dated path
+				l.compressing.Wait()
+				l.rollFile(file, l.datedPath(lastWrite))
 				return
 			}
-			// Get the maximum log file size from the environment variable
-			maxSizeStr := os.Getenv("MAX_LOG_FILE_SIZE")
-			maxSize := int64(1024 * 1024 * 5) // Default to 5MB if not set
-			if maxSizeStr != "" {
-				if parsedSize, err := strconv.ParseInt(maxSizeStr, 10, 64); err == nil {
-					maxSize = parsedSize
+
+			// Buffered writes track the file size themselves and only Stat the
+			// file every few writes
+			if l.buffer == nil || l.writesSinceStat >= bufferStatInterval {
+				fileInfo, err := file.Stat()
+				if err != nil {
+					return
 				}
+				l.fileSize = fileInfo.Size()
+				if l.buffer != nil {
+					l.fileSize += int64(l.buffer.Buffered())
+				}
+				l.writesSinceStat = 0
 			}
 
-			// File is smaller than 5MB keep it
-			if fileInfo.Size() < maxSize {
+			// File is smaller than the maximum size keep it
+			if l.fileSize < l.maxSize {
 				return
 			}
 
-			// Delete the last file if it exists
-			lastFile := fmt.Sprintf("%s.%02d", l.filename, 9)
-			if _, err := os.Stat(lastFile); err == nil {
-				os.Remove(lastFile)
-			}
-
-			for i := 9; i >= 1; i-- {
-				oldPath := fmt.Sprintf("%s.%02d", l.filename, i)
-				newPath := fmt.Sprintf("%s.%02d", l.filename, i+1)
-				if _, err := os.Stat(oldPath); err == nil {
-					if err := os.Rename(oldPath, newPath); err != nil {
-						return
+			// A previous rotation may still be compressing its file, let it finish
+			// before the generations are shifted
+			l.compressing.Wait()
+
+			// Delete the last file and any generation beyond it, left by a
+			// larger backup count, compressed or not
+			l.removeBackupsFrom(l.maxBackups)
+
+			for i := l.maxBackups - 1; i >= 1; i-- {
+				for _, suffix := range []string{"", ".gz"} {
+					oldPath := fmt.Sprintf("%s.%02d%s", l.filename, i, suffix)
+					newPath := fmt.Sprintf("%s.%02d%s", l.filename, i+1, suffix)
+					if _, err := os.Stat(oldPath); err == nil {
+						if err := os.Rename(oldPath, newPath); err != nil {
+							return
+						}
 					}
 				}
 			}
-			if err := os.Rename(l.filename, fmt.Sprintf("%s.01", l.filename)); err != nil {
-				return
-			}
-			file.Close()
-			file, err := os.OpenFile(l.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
-			if err != nil {
-				panic(err)
+			l.rollFile(file, fmt.Sprintf("%s.01", l.filename))
+		}
+	}
+}
+
+// removeBackupsFrom deletes the numbered rotations, like app.log.09 or
+// app.log.09.gz, whose generation is index or higher
+func (l *FileLogger) removeBackupsFrom(index int) {
+	dir, base := filepath.Split(l.filename)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), base+".")
+		if !ok {
+			continue
+		}
+		generation, err := strconv.Atoi(strings.TrimSuffix(suffix, ".gz"))
+		if err != nil || generation < index {
+			continue
+		}
+		os.Remove(filepath.Join(dir, entry.Name()))
+	}
+}
+
+// datedPath returns the daily rotation path for the day, a numeric suffix is
+// added when the file already exists so nothing is overwritten
+func (l *FileLogger) datedPath(day time.Time) string {
+	path := fmt.Sprintf("%s.%s", l.filename, day.Format(dailyRotationLayout))
+	candidate := path
+	for i := 1; ; i++ {
+		_, err := os.Stat(candidate)
+		_, gzErr := os.Stat(candidate + ".gz")
+		if os.IsNotExist(err) && os.IsNotExist(gzErr) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s.%d", path, i)
+	}
+}
+
+// rollFile moves the current file to rotatedPath and reopens a fresh file,
+// compressing the rotated one when enabled, the rotations are pruned once the
+// compression is done so the rotated file is only counted once
+func (l *FileLogger) rollFile(file *os.File, rotatedPath string) {
+	if l.buffer != nil {
+		l.buffer.Flush()
+	}
+	if err := os.Rename(l.filename, rotatedPath); err != nil {
+		return
+	}
+	file.Close()
+	file, err := os.OpenFile(l.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, l.fileMode)
+	if err != nil {
+		panic(err)
+	}
+	l.writer = file
+	l.fileSize = 0
+	if l.buffer != nil {
+		l.buffer.Reset(file)
+	}
+
+	filename, maxBackups, maxTotalSize := l.filename, l.maxBackups, l.maxTotalSize
+	if l.compressRotated {
+		l.compressing.Add(1)
+		go func() {
+			defer l.compressing.Done()
+			compressFile(rotatedPath, l.fileMode)
+			pruneRotated(filename, maxBackups, maxTotalSize)
+		}()
+		return
+	}
+	pruneRotated(filename, maxBackups, maxTotalSize)
+}
+
+// pruneRotated keeps the newest daily rotations up to the backup count and
+// deletes the oldest rotations, numbered or daily, until the file and its
+// rotations fit in the max total size
+func pruneRotated(filename string, maxBackups int, maxTotalSize int64) {
+	dated := datedRotations(filename)
+	if len(dated) > maxBackups {
+		for _, path := range dated[maxBackups:] {
+			os.Remove(path)
+		}
+		dated = dated[:maxBackups]
+	}
+
+	if maxTotalSize <= 0 {
+		return
+	}
+
+	paths := make([]string, 0)
+	for i := 1; i <= maxBackups; i++ {
+		for _, suffix := range []string{"", ".gz"} {
+			paths = append(paths, fmt.Sprintf("%s.%02d%s", filename, i, suffix))
+		}
+	}
+	paths = append(paths, dated...)
+
+	var total int64
+	if fileInfo, err := os.Stat(filename); err == nil {
+		total += fileInfo.Size()
+	}
+	rotations := make([]rotatedFile, 0, len(paths))
+	for _, path := range paths {
+		if fileInfo, err := os.Stat(path); err == nil {
+			total += fileInfo.Size()
+			rotations = append(rotations, rotatedFile{path: path, size: fileInfo.Size(), modTime: fileInfo.ModTime()})
+		}
+	}
+
+	// Rotations are ordered newest first so the oldest are removed from the end,
+	// numbered and daily rotations are merged by their modification time
+	sort.SliceStable(rotations, func(i, j int) bool {
+		return rotations[i].modTime.After(rotations[j].modTime)
+	})
+	for i := len(rotations) - 1; i >= 0 && total > maxTotalSize; i-- {
+		if err := os.Remove(rotations[i].path); err == nil {
+			total -= rotations[i].size
+		}
+	}
+}
+
+// rotatedFile is a rotation of the log file found by pruneRotated
+type rotatedFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// datedRotations returns the paths of the daily rotations, like app.log.2024-01-01
+// or app.log.2024-01-01.1.gz, newest first
+func datedRotations(filename string) []string {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	type datedRotation struct {
+		path  string
+		day   string
+		index int
+	}
+	rotations := make([]datedRotation, 0)
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), base+".")
+		if !ok {
+			continue
+		}
+		suffix = strings.TrimSuffix(suffix, ".gz")
+		if len(suffix) < len(dailyRotationLayout) {
+			continue
+		}
+		day := suffix[:len(dailyRotationLayout)]
+		if _, err := time.Parse(dailyRotationLayout, day); err != nil {
+			continue
+		}
+		index := 0
+		if rest := suffix[len(dailyRotationLayout):]; rest != "" {
+			index, err = strconv.Atoi(strings.TrimPrefix(rest, "."))
+			if err != nil || !strings.HasPrefix(rest, ".") {
+				continue
 			}
-			l.writer = file
 		}
+		rotations = append(rotations, datedRotation{path: filepath.Join(dir, entry.Name()), day: day, index: index})
+	}
+
+	sort.Slice(rotations, func(i, j int) bool {
+		if rotations[i].day != rotations[j].day {
+			return rotations[i].day > rotations[j].day
+		}
+		return rotations[i].index > rotations[j].index
+	})
+	paths := make([]string, 0, len(rotations))
+	for _, rotation := range rotations {
+		paths = append(paths, rotation.path)
+	}
+	return paths
+}
+
+// compressFile writes a gzip copy of path to path.gz with the file mode and
+// removes path, on failure the partial .gz is removed and the plain file is kept
+func compressFile(path string, mode os.FileMode) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(out)
+	_, err = io.Copy(writer, in)
+	if err == nil {
+		err = writer.Close()
 	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	in.Close()
+	return os.Remove(path)
 }
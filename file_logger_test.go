@@ -1,8 +1,11 @@
 package log
 
 import (
+	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -128,6 +131,466 @@ func TestFileLogger_RotateLogFile(t *testing.T) {
 	assert.Greater(t, rotatedFiles, 0, "Expected at least one rotated log file")
 }
 
+func TestFileLogger_CompressRotated(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "compress.log")
+
+	os.Setenv("MAX_LOG_FILE_SIZE", "100")
+	defer os.Unsetenv("MAX_LOG_FILE_SIZE")
+
+	logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+	logger.CompressRotated(true)
+
+	for i := 0; i < 10; i++ {
+		logger.Info("This is a long message that will help fill up the log file quickly " + fmt.Sprint(i))
+	}
+
+	// Close waits for the background compression to finish
+	logger.Close()
+
+	_, err := os.Stat(logFile + ".01")
+	assert.True(t, os.IsNotExist(err), "Expected the plain rotated file to be removed")
+
+	file, err := os.Open(logFile + ".01.gz")
+	assert.NoError(t, err)
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	assert.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "This is a long message")
+
+	files, err := os.ReadDir(tmpDir)
+	assert.NoError(t, err)
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), "compress.log.") {
+			assert.True(t, strings.HasSuffix(f.Name(), ".gz"), "Expected %s to be compressed", f.Name())
+		}
+	}
+}
+
+func TestFileLogger_MaxTotalSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "budget.log")
+
+	os.Setenv("MAX_LOG_FILE_SIZE", "100")
+	defer os.Unsetenv("MAX_LOG_FILE_SIZE")
+
+	logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+	logger.SetMaxTotalSize(350)
+
+	for i := 0; i < 20; i++ {
+		logger.Info("This is a long message that will help fill up the log file quickly " + fmt.Sprint(i))
+	}
+	logger.Close()
+
+	files, err := os.ReadDir(tmpDir)
+	assert.NoError(t, err)
+
+	var total int64
+	rotations := 0
+	for _, file := range files {
+		info, err := file.Info()
+		assert.NoError(t, err)
+		// The live file keeps growing after the last prune, so only the
+		// rotations are held to the budget
+		if strings.HasPrefix(file.Name(), "budget.log.") {
+			rotations++
+			total += info.Size()
+		}
+	}
+
+	assert.Greater(t, rotations, 0, "Expected at least one rotated log file")
+	assert.Less(t, rotations, 9, "Expected old rotations to be pruned")
+	assert.LessOrEqual(t, total, int64(350))
+	_, err = os.Stat(logFile + ".01")
+	assert.NoError(t, err, "Expected the newest rotation to be kept")
+}
+
+func TestFileLogger_MaxTotalSizeWithCompression(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "budget.log")
+
+	logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+	logger.SetMaxSize(1000)
+	logger.SetMaxTotalSize(700)
+	logger.CompressRotated(true)
+
+	// Every two messages fill a rotation that only fits the budget once it is
+	// compressed, waiting on each write keeps the live file size predictable
+	message := strings.Repeat("x", 500)
+	for i := 0; i < 7; i++ {
+		logger.Info(message)
+		logger.compressing.Wait()
+	}
+	logger.Close()
+
+	for _, path := range []string{logFile + ".01.gz", logFile + ".02.gz", logFile + ".03.gz"} {
+		_, err := os.Stat(path)
+		assert.NoError(t, err, "Expected %s to be kept", path)
+	}
+	_, err := os.Stat(logFile + ".01")
+	assert.True(t, os.IsNotExist(err), "Expected the newest rotation to be compressed")
+}
+
+func TestFileLogger_RotateDaily(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "daily.log")
+
+	now := time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+	logger.RotateDaily(true)
+
+	logger.Info("first day")
+	logger.Info("still first day")
+
+	now = now.Add(2 * time.Minute)
+	logger.Info("second day")
+	logger.Close()
+
+	rotated, err := os.ReadFile(logFile + ".2024-01-01")
+	assert.NoError(t, err)
+	assert.Contains(t, string(rotated), "first day")
+	assert.Contains(t, string(rotated), "still first day")
+	assert.NotContains(t, string(rotated), "second day")
+
+	current, err := os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "second day\n", string(current))
+}
+
+func TestFileLogger_RotateDailyPrunesDatedFiles(t *testing.T) {
+	t.Run("keeps the backup count", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "daily.log")
+
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		nowFunc = func() time.Time { return now }
+		defer func() { nowFunc = time.Now }()
+
+		logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+		logger.RotateDaily(true)
+		logger.SetMaxBackups(2)
+
+		for day := 0; day < 5; day++ {
+			logger.Info("day %d", day)
+			now = now.Add(24 * time.Hour)
+		}
+		logger.Info("today")
+		logger.Close()
+
+		for _, day := range []string{"2024-01-01", "2024-01-02", "2024-01-03"} {
+			_, err := os.Stat(logFile + "." + day)
+			assert.True(t, os.IsNotExist(err), "Expected %s to be pruned", day)
+		}
+		for _, day := range []string{"2024-01-04", "2024-01-05"} {
+			_, err := os.Stat(logFile + "." + day)
+			assert.NoError(t, err, "Expected %s to be kept", day)
+		}
+	})
+
+	t.Run("counts against the max total size", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "daily.log")
+
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		nowFunc = func() time.Time { return now }
+		defer func() { nowFunc = time.Now }()
+
+		logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+		logger.RotateDaily(true)
+		logger.SetMaxTotalSize(100)
+
+		message := strings.Repeat("x", 39)
+		for day := 0; day < 5; day++ {
+			logger.Info(message)
+			now = now.Add(24 * time.Hour)
+		}
+		logger.Info(message)
+		logger.Close()
+
+		// The budget is applied on rotation, before today's message is written
+		for _, day := range []string{"2024-01-01", "2024-01-02", "2024-01-03"} {
+			_, err := os.Stat(logFile + "." + day)
+			assert.True(t, os.IsNotExist(err), "Expected %s to be pruned", day)
+		}
+		for _, day := range []string{"2024-01-04", "2024-01-05"} {
+			_, err := os.Stat(logFile + "." + day)
+			assert.NoError(t, err, "Expected %s to be kept", day)
+		}
+	})
+}
+
+func TestFileLogger_SetMaxSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	auditFile := filepath.Join(tmpDir, "audit.log")
+	debugFile := filepath.Join(tmpDir, "debug.log")
+
+	auditLogger := FileLogger{filename: auditFile}.Init().(*FileLogger)
+	auditLogger.SetMaxSize(100)
+	debugLogger := FileLogger{filename: debugFile}.Init().(*FileLogger)
+
+	// The environment is only read on Init
+	os.Setenv("MAX_LOG_FILE_SIZE", "10")
+	defer os.Unsetenv("MAX_LOG_FILE_SIZE")
+
+	for i := 0; i < 10; i++ {
+		auditLogger.Info("This is a long message that will help fill up the log file quickly " + fmt.Sprint(i))
+		debugLogger.Info("This is a long message that will help fill up the log file quickly " + fmt.Sprint(i))
+	}
+	auditLogger.Close()
+	debugLogger.Close()
+
+	assert.Equal(t, int64(1024*1024*5), debugLogger.maxSize)
+	_, err := os.Stat(auditFile + ".01")
+	assert.NoError(t, err, "Expected the audit log to rotate")
+	_, err = os.Stat(debugFile + ".01")
+	assert.True(t, os.IsNotExist(err), "Expected the debug log not to rotate")
+}
+
+func TestFileLogger_Options(t *testing.T) {
+	t.Run("defaults without options", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "defaults.log")
+		logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+		defer logger.Close()
+
+		assert.Equal(t, int64(1024*1024*5), logger.maxSize)
+		assert.Equal(t, defaultMaxBackups, logger.maxBackups)
+		assert.Equal(t, defaultFileMode, logger.fileMode)
+		assert.False(t, logger.compressRotated)
+	})
+
+	t.Run("options applied on init", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "options.log")
+		logger := FileLogger{filename: logFile, options: []FileOption{
+			WithMaxSize(100),
+			WithMaxBackups(3),
+			WithFileMode(0o600),
+			WithCompression(),
+		}}.Init().(*FileLogger)
+
+		for i := 0; i < 10; i++ {
+			logger.Info("This is a long message that will help fill up the log file quickly " + fmt.Sprint(i))
+		}
+		logger.Close()
+
+		assert.Equal(t, int64(100), logger.maxSize)
+		assert.Equal(t, 3, logger.maxBackups)
+		info, err := os.Stat(logFile)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		_, err = os.Stat(logFile + ".01.gz")
+		assert.NoError(t, err, "Expected the rotated file to be compressed")
+	})
+
+	t.Run("service passes the options", func(t *testing.T) {
+		service := &LoggerService{LogLevel: Info}
+		service.AddFileLogger(filepath.Join(t.TempDir(), "service.log"), WithMaxBackups(2))
+		defer service.Close()
+
+		assert.Equal(t, 2, service.Loggers[0].(*FileLogger).maxBackups)
+	})
+}
+
+func TestFileLogger_SetMaxBackups(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxBackups int
+		expected   []string
+	}{
+		{name: "single backup", maxBackups: 1, expected: []string{"backups.log", "backups.log.01"}},
+		{name: "below one keeps the default", maxBackups: 0, expected: []string{"backups.log", "backups.log.01", "backups.log.02", "backups.log.03", "backups.log.04", "backups.log.05", "backups.log.06", "backups.log.07", "backups.log.08", "backups.log.09"}},
+		{name: "more than the default", maxBackups: 12, expected: []string{"backups.log", "backups.log.01", "backups.log.02", "backups.log.03", "backups.log.04", "backups.log.05", "backups.log.06", "backups.log.07", "backups.log.08", "backups.log.09", "backups.log.10", "backups.log.11", "backups.log.12"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			logger := FileLogger{filename: filepath.Join(tmpDir, "backups.log")}.Init().(*FileLogger)
+			logger.SetMaxSize(100)
+			logger.SetMaxBackups(tt.maxBackups)
+
+			for i := 0; i < 40; i++ {
+				logger.Info("This is a long message that will help fill up the log file quickly " + fmt.Sprint(i))
+			}
+			logger.Close()
+
+			files, err := os.ReadDir(tmpDir)
+			assert.NoError(t, err)
+			names := make([]string, 0, len(files))
+			for _, file := range files {
+				names = append(names, file.Name())
+			}
+			assert.Equal(t, tt.expected, names)
+
+			// The newest backup holds the messages logged just before the current file
+			content, err := os.ReadFile(filepath.Join(tmpDir, "backups.log.01"))
+			assert.NoError(t, err)
+			assert.Contains(t, string(content), "quickly 37")
+		})
+	}
+}
+
+func TestFileLogger_RotationRetention(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+		stale    []string
+	}{
+		{name: "plain rotations"},
+		{name: "compressed rotations", compress: true},
+		{name: "stale generations from a larger count", stale: []string{"retain.log.05", "retain.log.09.gz", "retain.log.10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for _, name := range tt.stale {
+				assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("old\n"), 0o666))
+			}
+			// Not a numbered rotation, it is left alone
+			assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "retain.log.2024-01-01"), []byte("day\n"), 0o666))
+
+			logger := FileLogger{filename: filepath.Join(tmpDir, "retain.log")}.Init().(*FileLogger)
+			logger.SetMaxSize(100)
+			logger.SetMaxBackups(3)
+			logger.CompressRotated(tt.compress)
+
+			for i := 0; i < 100; i++ {
+				logger.Info("This is a long message that will help fill up the log file quickly " + fmt.Sprint(i))
+			}
+			logger.Close()
+
+			files, err := os.ReadDir(tmpDir)
+			assert.NoError(t, err)
+			suffix := ""
+			if tt.compress {
+				suffix = ".gz"
+			}
+			names := make([]string, 0, len(files))
+			for _, file := range files {
+				names = append(names, file.Name())
+			}
+			assert.Equal(t, []string{"retain.log", "retain.log.01" + suffix, "retain.log.02" + suffix, "retain.log.03" + suffix, "retain.log.2024-01-01"}, names)
+		})
+	}
+}
+
+func TestFileLogger_UseBuffer(t *testing.T) {
+	t.Run("flush writes buffered messages", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "buffered.log")
+		logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+		logger.UseBuffer(4096)
+		defer logger.Close()
+
+		logger.Info("buffered message")
+
+		content, err := os.ReadFile(logFile)
+		assert.NoError(t, err)
+		assert.Empty(t, string(content))
+
+		assert.NoError(t, logger.Flush())
+		content, err = os.ReadFile(logFile)
+		assert.NoError(t, err)
+		assert.Equal(t, "buffered message\n", string(content))
+	})
+
+	t.Run("output matches unbuffered writes", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		os.Setenv("MAX_LOG_FILE_SIZE", "500")
+		defer os.Unsetenv("MAX_LOG_FILE_SIZE")
+
+		plain := FileLogger{filename: filepath.Join(tmpDir, "plain.log")}.Init().(*FileLogger)
+		buffered := FileLogger{filename: filepath.Join(tmpDir, "buffered.log")}.Init().(*FileLogger)
+		buffered.UseBuffer(64)
+
+		for i := 0; i < 30; i++ {
+			plain.Info("Message number %d with some padding", i)
+			buffered.Info("Message number %d with some padding", i)
+		}
+		plain.Close()
+		buffered.Close()
+
+		for _, suffix := range []string{"", ".01", ".02"} {
+			expected, err := os.ReadFile(filepath.Join(tmpDir, "plain.log"+suffix))
+			assert.NoError(t, err)
+			actual, err := os.ReadFile(filepath.Join(tmpDir, "buffered.log"+suffix))
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), string(actual), "file suffix %q", suffix)
+		}
+	})
+}
+
+func TestFileLogger_SyncOnError(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "audit.log")
+	logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+	logger.UseBuffer(4096)
+	logger.SyncOnError(true)
+	defer logger.Close()
+
+	logger.Info("routine message")
+	content, err := os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Empty(t, string(content))
+
+	logger.Error("login failed")
+	content, err = os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "routine message\nlogin failed\n", string(content))
+
+	logger.SyncOnError(false)
+	logger.Error("still buffered")
+	content, err = os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "routine message\nlogin failed\n", string(content))
+}
+
+func TestFileLogger_SetWriter(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "redirected.log")
+	logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+	logger.SetMaxSize(100)
+	logger.UseBuffer(4096)
+	logger.Info("before redirect")
+
+	var output bytes.Buffer
+	logger.SetWriter(&output)
+	for i := 0; i < 10; i++ {
+		logger.Info("This is a long message that will help fill up the log file quickly " + fmt.Sprint(i))
+	}
+	logger.Close()
+
+	content, err := os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "before redirect\n", string(content))
+	assert.Equal(t, 10, strings.Count(output.String(), "\n"))
+	_, err = os.Stat(logFile + ".01")
+	assert.True(t, os.IsNotExist(err), "Expected no rotation once redirected")
+}
+
+func TestFileLogger_WriteAfterClose(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "closed.log")
+	logger := FileLogger{filename: logFile}.Init().(*FileLogger)
+
+	logger.Info("before close")
+	logger.Close()
+	assert.False(t, logger.WouldLog(Info))
+
+	assert.NotPanics(t, func() {
+		logger.Info("after close")
+		logger.Error("after close %d", 1)
+		logger.UseBuffer(1024)
+		assert.NoError(t, logger.Flush())
+		logger.Close()
+	})
+
+	content, err := os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "before close\n", string(content))
+}
+
 func TestFileLogger_CorrelationID(t *testing.T) {
 	tmpFile := filepath.Join(t.TempDir(), "correlation.log")
 	logger := FileLogger{filename: tmpFile}.Init().(*FileLogger)
@@ -184,7 +647,7 @@ func TestFileLogger_AllLogMethods(t *testing.T) {
 		expect string
 	}{
 		{"Success", func() { logger.Success("success msg") }, "success msg"},
-		{"TaskSuccess", func() { logger.TaskSuccess("task success", true) }, "task success"},
+		{"TaskSuccess", func() { logger.TaskSuccess("task success", true) }, "task success [done]"},
 		{"Warn", func() { logger.Warn("warn msg") }, "warn msg"},
 		{"TaskWarn", func() { logger.TaskWarn("task warn") }, "task warn"},
 		{"Command", func() { logger.Command("command msg") }, "command msg"},
@@ -195,6 +658,7 @@ func TestFileLogger_AllLogMethods(t *testing.T) {
 		{"Fatal", func() { logger.Fatal("fatal msg") }, "fatal msg"},
 		{"LogError", func() { logger.LogError(errors.New("error msg")) }, "error msg"},
 		{"TaskError", func() { logger.TaskError("task error", true) }, "task error"},
+		{"TaskErrorWithArgs", func() { logger.TaskError("task %s failed", true, "backup") }, "task backup failed [done]"},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatMessage formats the words into the format like fmt.Sprintf, except
+// that words passed to a format without verbs are appended to it separated by
+// spaces, so Info("msg", a, b) reads "msg a b" instead of "msg%!(EXTRA ...)"
+func formatMessage(format string, words ...interface{}) string {
+	if len(words) == 0 || hasVerbs(format) {
+		return fmt.Sprintf(format, words...)
+	}
+
+	parts := make([]string, 0, len(words)+1)
+	parts = append(parts, strings.ReplaceAll(format, "%%", "%"))
+	for _, word := range words {
+		parts = append(parts, fmt.Sprintf("%v", word))
+	}
+	return strings.Join(parts, " ")
+}
+
+// hasVerbs reports whether the format has any verb, an escaped %% is not one
+func hasVerbs(format string) bool {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			i++
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// taskMessage returns the format and words of a task message, a message that
+// completes the task is formatted with the completion marker appended
+func taskMessage(format string, isComplete bool, words []interface{}) (string, []interface{}) {
+	if !isComplete {
+		return format, words
+	}
+	return escapeVerbs(strings.TrimSuffix(formatMessage(format, words...), "\n")) + taskCompleteMarker, nil
+}
@@ -0,0 +1,44 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		words    []interface{}
+		expected string
+	}{
+		{name: "no words", format: "started", expected: "started"},
+		{name: "verbs", format: "user %s logged in %d times", words: []interface{}{"bob", 3}, expected: "user bob logged in 3 times"},
+		{name: "words without verbs", format: "user logged in", words: []interface{}{"bob", 3}, expected: "user logged in bob 3"},
+		{name: "escaped percent is not a verb", format: "disk at 90%%", words: []interface{}{"sda"}, expected: "disk at 90% sda"},
+		{name: "escaped percent without words", format: "disk at 90%%", expected: "disk at 90%"},
+		{name: "verb after escaped percent", format: "100%% of %s", words: []interface{}{"files"}, expected: "100% of files"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatMessage(tt.format, tt.words...))
+		})
+	}
+}
+
+func TestFormatMessage_Loggers(t *testing.T) {
+	var output bytes.Buffer
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger, &CmdLogger{writer: &output}},
+	}
+
+	service.Info("cache warmed", "users", 120)
+
+	assert.Equal(t, "cache warmed users 120", mockLogger.LastPrintedMessage.Message)
+	assert.Equal(t, "\x1b[0mcache warmed users 120\x1b[0m\n", output.String())
+}
@@ -5,12 +5,12 @@ go 1.22
 require (
 	github.com/cjlapao/common-go v0.0.37
 	github.com/fatih/color v1.14.1
+	github.com/google/uuid v1.6.0
 	github.com/stretchr/testify v1.8.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.17 // indirect
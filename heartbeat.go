@@ -0,0 +1,49 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// heartbeatMessage is the text of the heartbeat line
+const heartbeatMessage = "heartbeat"
+
+// Heartbeat logs an info level "heartbeat" line every interval from a background
+// goroutine, so log aggregators watching an idle service still see it is alive.
+// The line goes through the usual level checks, so a level below Info silences
+// it. The returned func stops the heartbeat and waits for the goroutine to exit,
+// it is safe to call more than once.
+//
+// Example:
+//
+//	service := log.New()
+//	stop := service.Heartbeat(time.Minute)
+//	defer stop()
+//	// Output every minute: heartbeat
+func (l *LoggerService) Heartbeat(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				l.InfoCtx(context.Background(), heartbeatMessage)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+			<-stopped
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerService_Heartbeat(t *testing.T) {
+	t.Run("periodic emission and clean stop", func(t *testing.T) {
+		channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		_, ch := channelLogger.Subscribe("heartbeat", func(LogMessage) bool { return true })
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{channelLogger},
+		}
+
+		stop := service.Heartbeat(5 * time.Millisecond)
+		for i := 0; i < 3; i++ {
+			select {
+			case msg := <-ch:
+				assert.Equal(t, "info", msg.Level)
+				assert.Equal(t, "heartbeat", msg.Message)
+			case <-time.After(time.Second):
+				t.Fatal("Expected a heartbeat")
+			}
+		}
+		stop()
+		stop()
+
+		// Drain a heartbeat sent while stopping, then nothing else arrives
+		for len(ch) > 0 {
+			<-ch
+		}
+		time.Sleep(20 * time.Millisecond)
+		assert.Empty(t, ch)
+	})
+
+	t.Run("respects the log level", func(t *testing.T) {
+		channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		_, ch := channelLogger.Subscribe("heartbeat", func(LogMessage) bool { return true })
+		service := &LoggerService{
+			LogLevel: Error,
+			Loggers:  []Logger{channelLogger},
+		}
+
+		stop := service.Heartbeat(5 * time.Millisecond)
+		time.Sleep(30 * time.Millisecond)
+		stop()
+
+		assert.Empty(t, ch)
+	})
+}
@@ -1,5 +1,10 @@
 package log
 
+import (
+	"strings"
+	"unicode/utf8"
+)
+
 type LoggerIcon string
 
 const (
@@ -38,3 +43,83 @@ const (
 	IconThumbDown        LoggerIcon = "\xF0\x9F\x91\x8E"
 	IconPage             LoggerIcon = "\xF0\x9F\x93\x84"
 )
+
+// knownIcons lists every icon of the package, used to detect messages that
+// already start with one
+var knownIcons = []LoggerIcon{
+	IconHammer,
+	IconFire,
+	IconWrench,
+	IconKey,
+	IconLock,
+	IconOpenLock,
+	IconBell,
+	IconMagnifyingGlass,
+	IconBook,
+	IconBulb,
+	IconBomb,
+	IconLargeWhiteSquare,
+	IconCircle,
+	IconWarning,
+	IconRightArrow,
+	IconHourGlass,
+	IconInfo,
+	IconFlag,
+	IconRocket,
+	IconCheckMark,
+	IconCrossMark,
+	IconRevolvingLight,
+	IconBlackSquare,
+	IconFolder,
+	IconClipboard,
+	IconRightwardsArrow,
+	IconExclamationMark,
+	IconAsterisk,
+	IconRightHand,
+	IconCheckbox,
+	IconToilet,
+	IconThumbsUp,
+	IconThumbDown,
+	IconPage,
+}
+
+// defaultIconSeparator joins the icon and the message
+const defaultIconSeparator = " "
+
+// prependIcon joins the icon and the message with the separator, an empty
+// separator uses the default space
+func prependIcon(icon LoggerIcon, separator string, message string) string {
+	if separator == "" {
+		separator = defaultIconSeparator
+	}
+	return string(icon) + separator + message
+}
+
+// startsWithIcon reports whether the message already starts with a known icon
+// or an emoji, so the level icon is not prepended a second time
+func startsWithIcon(message string) bool {
+	message = strings.TrimLeft(message, " \t")
+	for _, icon := range knownIcons {
+		trimmed := strings.TrimSpace(string(icon))
+		if trimmed != "" && strings.HasPrefix(message, trimmed) {
+			return true
+		}
+	}
+
+	r, _ := utf8.DecodeRuneInString(message)
+	return isEmoji(r)
+}
+
+// isEmoji reports whether the rune belongs to the common emoji and pictograph
+// blocks
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // emoticons, pictographs, transport and flags
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // miscellaneous symbols and arrows
+		return true
+	}
+	return false
+}
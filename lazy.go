@@ -0,0 +1,171 @@
+package log
+
+import "sync"
+
+// levelScope is a level set with WithLevelScope until it is restored
+type levelScope struct {
+	level Level
+}
+
+// IsLevelEnabled reports whether messages at the level are logged by the
+// service, so callers can guard expensive blocks before logging.
+//
+// Example:
+//
+//	service := log.New().WithDebug()
+//	if service.IsLevelEnabled(log.Debug) {
+//	    service.Debug("state: %v", expensiveDump())
+//	}
+func (l *LoggerService) IsLevelEnabled(level Level) bool {
+	return l.level() >= level
+}
+
+// LogLevelValue returns the current log level of the service, messages above
+// it are not logged.
+//
+// Example:
+//
+//	service := log.New().WithDebug()
+//	fmt.Println(service.LogLevelValue())
+//	// Output: debug
+func (l *LoggerService) LogLevelValue() Level {
+	return l.level()
+}
+
+// level returns the service log level, it is read under the level lock so it
+// can change while other goroutines log, see WithLevelScope
+func (l *LoggerService) level() Level {
+	l.levelMutex.RLock()
+	defer l.levelMutex.RUnlock()
+
+	return l.LogLevel
+}
+
+// setLevel sets the service log level under the level lock
+func (l *LoggerService) setLevel(level Level) {
+	l.levelMutex.Lock()
+	defer l.levelMutex.Unlock()
+
+	l.LogLevel = level
+}
+
+// WithLevelScope sets the log level until the returned function is called,
+// which restores the previous level and is meant to be deferred so it also
+// runs on a panic. The level applies to the whole service, not only to the
+// calling goroutine. Scopes can overlap, the level of the most recent open
+// scope is used and the level from before the first scope is restored once
+// they are all closed. Calling the restore function again does nothing.
+// It is safe to log from other goroutines while scopes open and close, as
+// long as the level is changed through the service methods rather than by
+// assigning LogLevel.
+//
+// Example:
+//
+//	service := log.New()
+//	restore := service.WithLevelScope(log.Debug)
+//	defer restore()
+//	service.Debug("This will be logged until restore is called")
+func (l *LoggerService) WithLevelScope(level Level) func() {
+	l.scopeMutex.Lock()
+	defer l.scopeMutex.Unlock()
+
+	if len(l.levelScopes) == 0 {
+		l.scopeBaseLevel = l.level()
+	}
+	scope := &levelScope{level: level}
+	l.levelScopes = append(l.levelScopes, scope)
+	l.setLevel(level)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.closeLevelScope(scope) })
+	}
+}
+
+// closeLevelScope removes the scope and applies the level of the most recent
+// scope still open, or the level from before the first one
+func (l *LoggerService) closeLevelScope(scope *levelScope) {
+	l.scopeMutex.Lock()
+	defer l.scopeMutex.Unlock()
+
+	for i, open := range l.levelScopes {
+		if open == scope {
+			l.levelScopes = append(l.levelScopes[:i], l.levelScopes[i+1:]...)
+			break
+		}
+	}
+
+	if len(l.levelScopes) == 0 {
+		l.setLevel(l.scopeBaseLevel)
+		return
+	}
+	l.setLevel(l.levelScopes[len(l.levelScopes)-1].level)
+}
+
+// ErrorFn logs the message returned by fn at error level, fn is only called
+// when the level is enabled.
+//
+// Example:
+//
+//	service := log.New()
+//	service.ErrorFn(func() string { return "failed jobs: " + describeFailures() })
+func (l *LoggerService) ErrorFn(fn func() string) {
+	if l.IsLevelEnabled(Error) {
+		l.Error("%s", fn())
+	}
+}
+
+// WarnFn logs the message returned by fn at warning level, fn is only called
+// when the level is enabled.
+//
+// Example:
+//
+//	service := log.New()
+//	service.WarnFn(func() string { return "slow queries: " + describeSlowQueries() })
+func (l *LoggerService) WarnFn(fn func() string) {
+	if l.IsLevelEnabled(Warning) {
+		l.Warn("%s", fn())
+	}
+}
+
+// InfoFn logs the message returned by fn at info level, fn is only called
+// when the level is enabled.
+//
+// Example:
+//
+//	service := log.New()
+//	service.InfoFn(func() string { return "config: " + describeConfig() })
+func (l *LoggerService) InfoFn(fn func() string) {
+	if l.IsLevelEnabled(Info) {
+		l.Info("%s", fn())
+	}
+}
+
+// DebugFn logs the message returned by fn at debug level, fn is only called
+// when the level is enabled. Unlike Debug, a skipped message is not kept by
+// the memory loggers as it is never built.
+//
+// Example:
+//
+//	service := log.New()
+//	service.DebugFn(func() string { return "state: " + expensiveDump() })
+//	// expensiveDump is not called, the service is at info
+func (l *LoggerService) DebugFn(fn func() string) {
+	if l.IsLevelEnabled(Debug) {
+		l.Debug("%s", fn())
+	}
+}
+
+// TraceFn logs the message returned by fn at trace level, fn is only called
+// when the level is enabled. Unlike Trace, a skipped message is not kept by
+// the memory loggers as it is never built.
+//
+// Example:
+//
+//	service := log.New().WithTrace()
+//	service.TraceFn(func() string { return "payload: " + string(body) })
+func (l *LoggerService) TraceFn(fn func() string) {
+	if l.IsLevelEnabled(Trace) {
+		l.Trace("%s", fn())
+	}
+}
@@ -0,0 +1,154 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerService_IsLevelEnabled(t *testing.T) {
+	service := &LoggerService{LogLevel: Info}
+
+	assert.True(t, service.IsLevelEnabled(Error))
+	assert.True(t, service.IsLevelEnabled(Warning))
+	assert.True(t, service.IsLevelEnabled(Info))
+	assert.False(t, service.IsLevelEnabled(Debug))
+	assert.False(t, service.IsLevelEnabled(Trace))
+}
+
+func TestLoggerService_LogLevelValue(t *testing.T) {
+	service := &LoggerService{LogLevel: Info}
+	assert.Equal(t, Info, service.LogLevelValue())
+
+	service.WithTrace()
+	assert.Equal(t, Trace, service.LogLevelValue())
+	assert.True(t, service.IsLevelEnabled(Trace))
+}
+
+func TestLoggerService_LazyLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   Level
+		log     func(service *LoggerService, fn func() string)
+		enabled bool
+	}{
+		{name: "error", level: Error, log: (*LoggerService).ErrorFn, enabled: true},
+		{name: "warn at error", level: Error, log: (*LoggerService).WarnFn, enabled: false},
+		{name: "warn", level: Warning, log: (*LoggerService).WarnFn, enabled: true},
+		{name: "info at warning", level: Warning, log: (*LoggerService).InfoFn, enabled: false},
+		{name: "info", level: Info, log: (*LoggerService).InfoFn, enabled: true},
+		{name: "debug at info", level: Info, log: (*LoggerService).DebugFn, enabled: false},
+		{name: "debug", level: Debug, log: (*LoggerService).DebugFn, enabled: true},
+		{name: "trace at debug", level: Debug, log: (*LoggerService).TraceFn, enabled: false},
+		{name: "trace", level: Trace, log: (*LoggerService).TraceFn, enabled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := &MockLogger{}
+			service := &LoggerService{
+				LogLevel: tt.level,
+				Loggers:  []Logger{mockLogger},
+			}
+
+			called := false
+			tt.log(service, func() string {
+				called = true
+				return "100% built"
+			})
+
+			assert.Equal(t, tt.enabled, called)
+			if tt.enabled {
+				assert.Len(t, mockLogger.PrintedMessages, 1)
+				assert.Equal(t, "100% built", mockLogger.LastPrintedMessage.Message)
+			} else {
+				assert.Empty(t, mockLogger.PrintedMessages)
+			}
+		})
+	}
+}
+
+func TestLoggerService_WithLevelScope(t *testing.T) {
+	t.Run("restores the previous level", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{LogLevel: Info, Loggers: []Logger{mockLogger}}
+
+		restore := service.WithLevelScope(Debug)
+		service.Debug("inside the scope")
+		restore()
+		restore()
+		service.Debug("after the scope")
+
+		assert.Equal(t, Info, service.LogLevel)
+		assert.Len(t, mockLogger.PrintedMessages, 1)
+		assert.Equal(t, "inside the scope", mockLogger.LastPrintedMessage.Message)
+	})
+
+	t.Run("restores on panic", func(t *testing.T) {
+		service := &LoggerService{LogLevel: Warning}
+
+		assert.Panics(t, func() {
+			defer service.WithLevelScope(Trace)()
+			panic("tricky operation failed")
+		})
+		assert.Equal(t, Warning, service.LogLevel)
+	})
+
+	t.Run("overlapping scopes", func(t *testing.T) {
+		service := &LoggerService{LogLevel: Info}
+
+		restoreDebug := service.WithLevelScope(Debug)
+		restoreTrace := service.WithLevelScope(Trace)
+		assert.Equal(t, Trace, service.LogLevel)
+
+		// Closing the older scope first keeps the newer one
+		restoreDebug()
+		assert.Equal(t, Trace, service.LogLevel)
+		restoreTrace()
+		assert.Equal(t, Info, service.LogLevel)
+	})
+
+	t.Run("concurrent scopes", func(t *testing.T) {
+		service := &LoggerService{LogLevel: Info}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer service.WithLevelScope(Debug)()
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, Info, service.LogLevel)
+		assert.Empty(t, service.levelScopes)
+	})
+
+	t.Run("logging while scopes open and close", func(t *testing.T) {
+		service := &LoggerService{LogLevel: Info, Loggers: []Logger{&NullLogger{}}}
+		handler := NewSlogHandler(service)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				defer service.WithLevelScope(Trace)()
+			}()
+			go func() {
+				defer wg.Done()
+				service.IsLevelEnabled(Debug)
+				service.Debug("while a scope may be open")
+				service.TaskWarn("task while a scope may be open")
+				handler.Enabled(context.Background(), slog.LevelDebug)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, Info, service.LogLevelValue())
+	})
+}
@@ -1,5 +1,10 @@
 package log
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Level Entity
 type Level int
 
@@ -12,6 +17,49 @@ const (
 	Trace
 )
 
+// String returns the level name as emitted by the loggers, e.g. "warn"
 func (l Level) String() string {
-	return []string{"error", "warning", "info", "debug", "trace"}[l]
+	return []string{"error", "warn", "info", "debug", "trace"}[l]
+}
+
+// levelFromName maps the level names used by the loggers when printing a
+// message to a Level, the informational names such as "success" map to Info
+func levelFromName(name string) Level {
+	switch name {
+	case "error":
+		return Error
+	case "warn":
+		return Warning
+	case "debug":
+		return Debug
+	case "trace":
+		return Trace
+	default:
+		return Info
+	}
+}
+
+// ParseLevel parses a level name, case insensitive, into a Level. It accepts
+// error, warn, warning, info, debug and trace and returns an error for any
+// other name.
+//
+// Example:
+//
+//	level, err := log.ParseLevel("WARN")
+//	// level == log.Warning, err == nil
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "error":
+		return Error, nil
+	case "warn", "warning":
+		return Warning, nil
+	case "info":
+		return Info, nil
+	case "debug":
+		return Debug, nil
+	case "trace":
+		return Trace, nil
+	default:
+		return Info, fmt.Errorf("unknown log level %q", s)
+	}
 }
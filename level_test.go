@@ -0,0 +1,91 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Level
+		wantErr  bool
+	}{
+		{input: "error", expected: Error},
+		{input: "WARN", expected: Warning},
+		{input: "Warning", expected: Warning},
+		{input: "info", expected: Info},
+		{input: " debug ", expected: Debug},
+		{input: "TRACE", expected: Trace},
+		{input: "verbose", expected: Info, wantErr: true},
+		{input: "", expected: Info, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := ParseLevel(tt.input)
+			assert.Equal(t, tt.expected, level)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoggerService_SetLevelFromString(t *testing.T) {
+	service := &LoggerService{LogLevel: Info}
+
+	assert.NoError(t, service.SetLevelFromString("warn"))
+	assert.Equal(t, Warning, service.LogLevel)
+
+	assert.EqualError(t, service.SetLevelFromString("loud"), `unknown log level "loud"`)
+	assert.Equal(t, Warning, service.LogLevel)
+}
+
+func TestLoggerService_LogLevelFromEnv(t *testing.T) {
+	tests := []struct {
+		env      string
+		expected Level
+	}{
+		{env: "error", expected: Error},
+		{env: "warn", expected: Warning},
+		{env: "info", expected: Info},
+		{env: "debug", expected: Debug},
+		{env: "trace", expected: Trace},
+		{env: "unknown", expected: Info},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(LOG_LEVEL, tt.env)
+			service := NewIsolated()
+			assert.Equal(t, tt.expected, service.LogLevel)
+		})
+	}
+}
+
+func TestLevel_String(t *testing.T) {
+	for _, level := range []Level{Error, Warning, Info, Debug, Trace} {
+		t.Run(level.String(), func(t *testing.T) {
+			assert.Equal(t, level, levelFromName(level.String()))
+
+			parsed, err := ParseLevel(level.String())
+			assert.NoError(t, err)
+			assert.Equal(t, level, parsed)
+		})
+	}
+
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+	service.Log("disk almost full", Warning)
+	service.Warn("disk almost full")
+
+	assert.Equal(t, Warning.String(), mockLogger.PrintedMessages[0].Level)
+	assert.Equal(t, mockLogger.PrintedMessages[0].Level, mockLogger.PrintedMessages[1].Level)
+}
@@ -0,0 +1,186 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LogEntry accumulates structured key-value fields that are attached to the
+// next message logged through it. Entries are immutable, each WithField call
+// returns a new entry so fields never leak between separate messages.
+//
+// Example:
+//
+//	service := log.New()
+//	service.WithFields(map[string]any{"user_id": 42, "route": "/login"}).Info("request handled")
+//	// Output: request handled route=/login user_id=42
+type LogEntry struct {
+	service *LoggerService
+	ctx     context.Context
+	fields  map[string]any
+}
+
+// WithField returns a LogEntry carrying the given key-value pair.
+//
+// Example:
+//
+//	service := log.New()
+//	service.WithField("user_id", 42).Info("user logged in")
+//	// Output: user logged in user_id=42
+func (l *LoggerService) WithField(key string, value any) *LogEntry {
+	return l.WithFields(map[string]any{key: value})
+}
+
+// WithFields returns a LogEntry carrying all the given key-value pairs.
+//
+// Example:
+//
+//	service := log.New()
+//	service.WithFields(map[string]any{"user_id": 42, "route": "/login"}).Info("request handled")
+//	// Output: request handled route=/login user_id=42
+func (l *LoggerService) WithFields(fields map[string]any) *LogEntry {
+	entry := &LogEntry{service: l}
+	return entry.WithFields(fields)
+}
+
+// WithField returns a copy of the entry with the key-value pair added
+func (e *LogEntry) WithField(key string, value any) *LogEntry {
+	return e.WithFields(map[string]any{key: value})
+}
+
+// WithFields returns a copy of the entry with all the key-value pairs added
+func (e *LogEntry) WithFields(fields map[string]any) *LogEntry {
+	result := &LogEntry{
+		service: e.service,
+		ctx:     e.ctx,
+		fields:  make(map[string]any, len(e.fields)+len(fields)),
+	}
+	for key, value := range e.fields {
+		result.fields[key] = value
+	}
+	for key, value := range fields {
+		result.fields[key] = value
+	}
+
+	return result
+}
+
+// Fields returns a copy of the fields attached to the entry
+func (e *LogEntry) Fields() map[string]any {
+	fields := make(map[string]any, len(e.fields))
+	for key, value := range e.fields {
+		fields[key] = value
+	}
+
+	return fields
+}
+
+// context returns a context carrying the entry fields for the service *Ctx
+// methods, derived from the entry context when it has one
+func (e *LogEntry) context() context.Context {
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, fieldsContextKey, e.fields)
+}
+
+// appendFields appends the fields as key=value pairs sorted by key to the format string
+func appendFields(format string, fields map[string]any) string {
+	if len(fields) == 0 {
+		return format
+	}
+
+	// fields are literal text, escape them so they are not read as verbs
+	return format + " " + escapeVerbs(fieldsText(fields))
+}
+
+// fieldsText renders the fields as key=value pairs sorted by key
+func fieldsText(fields map[string]any) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+fieldValue(fields[key]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// fieldValue renders a field value for a key=value pair, quoting values with
+// spaces, equal signs or quotes
+func fieldValue(value any) string {
+	text := fmt.Sprintf("%v", value)
+	if strings.ContainsAny(text, " =\"") {
+		text = strconv.Quote(text)
+	}
+	return text
+}
+
+// escapeVerbs escapes the percent signs of literal text appended to a format
+// string so they are not read as verbs
+func escapeVerbs(text string) string {
+	return strings.ReplaceAll(text, "%", "%%")
+}
+
+// Info logs an informational message with the entry fields
+func (e *LogEntry) Info(format string, words ...interface{}) {
+	e.service.InfoCtx(e.context(), format, words...)
+}
+
+// Success logs a success message with the entry fields
+func (e *LogEntry) Success(format string, words ...interface{}) {
+	e.service.SuccessCtx(e.context(), format, words...)
+}
+
+// Warn logs a warning message with the entry fields
+func (e *LogEntry) Warn(format string, words ...interface{}) {
+	e.service.WarnCtx(e.context(), format, words...)
+}
+
+// Command logs a command execution message with the entry fields
+func (e *LogEntry) Command(format string, words ...interface{}) {
+	e.service.CommandCtx(e.context(), format, words...)
+}
+
+// Disabled logs a disabled feature message with the entry fields
+func (e *LogEntry) Disabled(format string, words ...interface{}) {
+	e.service.DisabledCtx(e.context(), format, words...)
+}
+
+// Notice logs a notice message with the entry fields
+func (e *LogEntry) Notice(format string, words ...interface{}) {
+	e.service.NoticeCtx(e.context(), format, words...)
+}
+
+// Debug logs a debug message with the entry fields
+func (e *LogEntry) Debug(format string, words ...interface{}) {
+	e.service.DebugCtx(e.context(), format, words...)
+}
+
+// Trace logs a trace message with the entry fields
+func (e *LogEntry) Trace(format string, words ...interface{}) {
+	e.service.TraceCtx(e.context(), format, words...)
+}
+
+// Error logs an error message with the entry fields
+func (e *LogEntry) Error(format string, words ...interface{}) {
+	e.service.ErrorCtx(e.context(), format, words...)
+}
+
+// Exception logs an error with additional context and the entry fields,
+// the fields are appended after the error text
+func (e *LogEntry) Exception(err error, format string, words ...interface{}) {
+	e.service.ExceptionCtx(e.context(), err, format, words...)
+}
+
+// Fatal logs a fatal error message with the entry fields
+func (e *LogEntry) Fatal(format string, words ...interface{}) {
+	e.service.FatalCtx(e.context(), format, words...)
+}
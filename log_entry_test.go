@@ -0,0 +1,137 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerService_WithFields(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+
+	t.Run("fields are appended sorted by key", func(t *testing.T) {
+		service.WithFields(map[string]any{"user_id": 42, "route": "/login"}).Info("request %s", "handled")
+
+		assert.Equal(t, "request handled route=/login user_id=42", mockLogger.LastPrintedMessage.Message)
+		assert.Equal(t, "info", mockLogger.LastPrintedMessage.Level)
+	})
+
+	t.Run("values with spaces are quoted", func(t *testing.T) {
+		service.WithField("agent", "curl 8.0").Warn("slow request")
+
+		assert.Equal(t, `slow request agent="curl 8.0"`, mockLogger.LastPrintedMessage.Message)
+		assert.Equal(t, "warn", mockLogger.LastPrintedMessage.Level)
+	})
+
+	t.Run("percent signs in fields are not read as verbs", func(t *testing.T) {
+		service.WithField("usage", "90%").Info("disk %s", "full")
+
+		assert.Equal(t, "disk full usage=90%", mockLogger.LastPrintedMessage.Message)
+	})
+
+	t.Run("exception appends fields after the error", func(t *testing.T) {
+		service.WithField("file", "config.json").Exception(errors.New("not found"), "failed to load")
+
+		assert.Equal(t, "failed to load, err not found file=config.json", mockLogger.LastPrintedMessage.Message)
+		assert.Equal(t, "error", mockLogger.LastPrintedMessage.Level)
+	})
+
+	t.Run("respects the service log level", func(t *testing.T) {
+		mockLogger.Clear()
+		service.WithField("key", "value").Debug("hidden")
+
+		assert.Empty(t, mockLogger.PrintedMessages)
+	})
+}
+
+func TestLogEntry_FieldsDoNotLeak(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+
+	base := service.WithField("request", "abc")
+	withUser := base.WithField("user", "bob")
+	base.WithField("other", 1)
+
+	withUser.Info("first")
+	assert.Equal(t, "first request=abc user=bob", mockLogger.LastPrintedMessage.Message)
+
+	base.Info("second")
+	assert.Equal(t, "second request=abc", mockLogger.LastPrintedMessage.Message)
+
+	service.Info("third")
+	assert.Equal(t, "third", mockLogger.LastPrintedMessage.Message)
+
+	fields := base.Fields()
+	fields["mutated"] = true
+	assert.NotContains(t, base.Fields(), "mutated")
+}
+
+func TestLogEntry_StructuredFields(t *testing.T) {
+	channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{channelLogger, mockLogger},
+	}
+	_, ch := channelLogger.Subscribe("fields", func(LogMessage) bool { return true })
+
+	service.WithFields(map[string]any{
+		"request": map[string]any{"route": "/login", "tags": []string{"auth", "web"}},
+		"handler": func() {},
+	}).Info("request handled")
+
+	msg := <-ch
+	assert.Equal(t, "request handled", msg.Message)
+
+	data, err := json.Marshal(msg)
+	assert.NoError(t, err)
+
+	var decoded struct {
+		Fields struct {
+			Request struct {
+				Route string   `json:"route"`
+				Tags  []string `json:"tags"`
+			} `json:"request"`
+			Handler string `json:"handler"`
+		} `json:"fields"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "/login", decoded.Fields.Request.Route)
+	assert.Equal(t, []string{"auth", "web"}, decoded.Fields.Request.Tags)
+	assert.NotEmpty(t, decoded.Fields.Handler, "Expected the unmarshalable value to fall back to its text")
+
+	// Text loggers still get the flattened key=value pairs
+	assert.Contains(t, mockLogger.LastPrintedMessage.Message, "request=\"map[route:/login tags:[auth web]]\"")
+}
+
+func TestLoggerService_WithBuildInfo(t *testing.T) {
+	channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{channelLogger, mockLogger},
+	}
+	service.WithBuildInfo("1.4.0", "a1b2c3d", "")
+	_, ch := channelLogger.Subscribe("build", func(LogMessage) bool { return true })
+
+	service.Info("server started")
+	service.WithField("commit", "override").Warn("entry %s", "fields")
+
+	msg := <-ch
+	assert.Equal(t, map[string]any{"version": "1.4.0", "commit": "a1b2c3d"}, msg.Fields)
+	assert.Equal(t, "server started", msg.Message)
+	assert.Equal(t, "server started commit=a1b2c3d version=1.4.0", mockLogger.PrintedMessages[0].Message)
+
+	msg = <-ch
+	assert.Equal(t, map[string]any{"version": "1.4.0", "commit": "override"}, msg.Fields)
+	assert.Equal(t, "entry fields commit=override version=1.4.0", mockLogger.PrintedMessages[1].Message)
+}
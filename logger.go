@@ -1,6 +1,9 @@
 package log
 
 import (
+	"io"
+	"time"
+
 	"github.com/cjlapao/common-go/strcolor"
 )
 
@@ -29,3 +32,95 @@ type Logger interface {
 	Fatal(format string, words ...interface{})
 	FatalError(e error, format string, words ...interface{})
 }
+
+// correlatedLogger is implemented by loggers that can print a message using a
+// correlation id resolved by the LoggerService, used by the *Ctx methods
+type correlatedLogger interface {
+	printCorrelated(correlationId string, format string, icon LoggerIcon, level string, words ...interface{})
+}
+
+// structuredLogger is implemented by loggers producing structured messages that
+// keep the correlation id, source and fields of a message apart from its text
+type structuredLogger interface {
+	printStructured(correlationId string, meta messageMeta, format string, icon LoggerIcon, level string, words ...interface{})
+}
+
+// messageMeta carries what the service knows about a message besides its text,
+// for the structured loggers
+type messageMeta struct {
+	source  string
+	fields  map[string]any
+	sampled bool
+	repeat  int
+}
+
+// apply copies the meta into the structured message
+func (m messageMeta) apply(msg *LogMessage) {
+	msg.Fields = structuredFields(m.fields)
+	msg.Source = m.source
+	msg.Sampled = m.sampled
+	msg.Repeat = m.repeat
+}
+
+// taskLogger is implemented by loggers rendering the progress of long
+// operations, isComplete marks the message that ends the task
+type taskLogger interface {
+	TaskSuccess(format string, isComplete bool, words ...interface{})
+	TaskWarn(format string, words ...interface{})
+	TaskError(format string, isComplete bool, words ...interface{})
+}
+
+// keyedLogger is implemented by loggers that can be registered more than once
+// with different destinations, the service keeps one logger per type and key
+type keyedLogger interface {
+	registrationKey() string
+}
+
+// uptimeLogger is implemented by loggers that can render timestamps as the
+// elapsed time since the service was created
+type uptimeLogger interface {
+	UseUptimeTimestamp(startedAt time.Time)
+}
+
+// schemaVersionLogger is implemented by loggers producing structured messages
+// that can carry a schema version
+type schemaVersionLogger interface {
+	SetSchemaVersion(version string)
+}
+
+// correlationEnvLogger is implemented by loggers reading the correlation id
+// from an environment variable whose name can be changed
+type correlationEnvLogger interface {
+	SetCorrelationEnv(name string)
+}
+
+// colorSchemeLogger is implemented by command line loggers whose level colors
+// can be overridden
+type colorSchemeLogger interface {
+	SetLevelColor(level Level, code ColorCode)
+}
+
+// levelChecker is implemented by loggers that may drop messages on their own,
+// WouldLog reports whether a message at the level would actually be emitted
+type levelChecker interface {
+	WouldLog(level Level) bool
+}
+
+// writerSettable is implemented by loggers writing to an io.Writer that can
+// be replaced, used by SetOutput
+type writerSettable interface {
+	SetWriter(w io.Writer)
+}
+
+// batchLogger is implemented by loggers with per message work that can be done
+// once for a whole LogBatch, endBatch is called after the last entry
+type batchLogger interface {
+	beginBatch()
+	endBatch()
+}
+
+// Flusher is implemented by loggers that buffer or write asynchronously,
+// Flush returns once every message logged so far has reached its destination
+type Flusher interface {
+	Flush() error
+}
@@ -1,5 +1,10 @@
 package log
 
+import (
+	"io"
+	"os"
+)
+
 // LogOptions Definition
 type LoggerOptions int64
 
@@ -7,3 +12,79 @@ const (
 	WithTimestamp LoggerOptions = iota
 	WithCorrelationId
 )
+
+// Option configures the service built by NewWithOptions
+type Option func(*serviceOptions)
+
+// serviceOptions collects the Option values before the service is built
+type serviceOptions struct {
+	writer     io.Writer
+	level      Level
+	levelSet   bool
+	timestamps bool
+	noCmd      bool
+}
+
+// WithWriter makes the command line logger write to w instead of stdout
+func WithWriter(w io.Writer) Option {
+	return func(o *serviceOptions) {
+		o.writer = w
+	}
+}
+
+// WithLevel sets the log level, overriding LOG_LEVEL
+func WithLevel(level Level) Option {
+	return func(o *serviceOptions) {
+		o.level = level
+		o.levelSet = true
+	}
+}
+
+// WithTimestamps enables timestamp prefixing for all log messages
+func WithTimestamps() Option {
+	return func(o *serviceOptions) {
+		o.timestamps = true
+	}
+}
+
+// WithoutCmdLogger leaves the command line logger out, only the channel
+// logger is registered
+func WithoutCmdLogger() Option {
+	return func(o *serviceOptions) {
+		o.noCmd = true
+	}
+}
+
+// NewWithOptions creates the global logger like New, configured by the options
+// before any logger is registered, so the command line logger never writes to
+// stdout when a writer is given and no settings need to be changed afterwards.
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	service := log.NewWithOptions(log.WithWriter(&buf), log.WithLevel(log.Debug), log.WithTimestamps())
+//	service.Debug("Hello")
+//	// buf: 2024-03-20T10:00:00Z Hello
+func NewWithOptions(opts ...Option) *LoggerService {
+	options := serviceOptions{writer: os.Stdout}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	service := newService()
+	if options.levelSet {
+		service.LogLevel = options.level
+	}
+	if options.timestamps {
+		service.WithTimestamp()
+	}
+	if !options.noCmd {
+		cmd := CmdLogger{}.Init().(*CmdLogger)
+		cmd.SetWriter(options.writer)
+		service.AddLogger(cmd)
+	}
+	service.AddChannelLogger()
+
+	globalLogger = service
+	return globalLogger
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	t.Run("defaults like New", func(t *testing.T) {
+		service := NewWithOptions()
+		defer service.Close()
+
+		assert.Same(t, service, Get())
+		assert.Equal(t, Info, service.LogLevel)
+		assert.Len(t, service.Loggers, 2)
+		assert.IsType(t, &CmdLogger{}, service.Loggers[0])
+		assert.IsType(t, &ChannelLogger{}, service.Loggers[1])
+	})
+
+	t.Run("writer, level and timestamps", func(t *testing.T) {
+		t.Setenv(LOG_LEVEL, "error")
+		var output bytes.Buffer
+		service := NewWithOptions(WithWriter(&output), WithLevel(Debug), WithTimestamps())
+		defer service.Close()
+
+		service.Debug("hello")
+
+		assert.Equal(t, Debug, service.LogLevel)
+		assert.True(t, service.Loggers[0].IsTimestampEnabled())
+		assert.Regexp(t, `^\S+Z hello\n$`, output.String())
+	})
+
+	t.Run("without command line logger", func(t *testing.T) {
+		service := NewWithOptions(WithoutCmdLogger())
+		defer service.Close()
+
+		assert.Len(t, service.Loggers, 1)
+		assert.IsType(t, &ChannelLogger{}, service.Loggers[0])
+	})
+}
@@ -1,8 +1,15 @@
 package log
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 )
 
 // AddCmdLogger adds a command line logger to the LoggerService.
@@ -17,7 +24,7 @@ import (
 //	service.Info("Hello from command line!")
 //	// Output: [2024-03-20T10:00:00Z] ℹ info: Hello from command line!
 func (l *LoggerService) AddCmdLogger() {
-	Register(&CmdLogger{
+	l.register(&CmdLogger{
 		useTimestamp:      l.UseTimestamp,
 		userCorrelationId: l.useCorrelationId,
 		useIcons:          l.useIcons,
@@ -27,43 +34,81 @@ func (l *LoggerService) AddCmdLogger() {
 // AddFileLogger adds a file logger to the LoggerService.
 // The file logger writes formatted log messages to the specified file.
 // It inherits timestamp, correlation ID, and icon settings from the LoggerService.
+// The options set the rotation size, backup count, file mode and compression,
+// without options the file rotates at MAX_LOG_FILE_SIZE, 5MB by default, and
+// keeps 9 backups.
 //
 // Example:
 //
 //	service := log.New()
 //	service.WithTimestamp()
-//	service.AddFileLogger("app.log")
+//	service.AddFileLogger("app.log", log.WithMaxSize(10*1024*1024), log.WithMaxBackups(3), log.WithCompression())
 //	service.Info("Hello from file logger!")
 //	// Content of app.log: [2024-03-20T10:00:00Z] info: Hello from file logger!
-func (l *LoggerService) AddFileLogger(filename string) {
-	Register(&FileLogger{
+func (l *LoggerService) AddFileLogger(filename string, opts ...FileOption) {
+	l.register(&FileLogger{
 		userCorrelationId: l.useCorrelationId,
 		useIcons:          l.useIcons,
 		useTimestamp:      l.UseTimestamp,
 		filename:          filename,
+		options:           opts,
 	})
 }
 
 // AddChannelLogger adds a channel-based logger to the LoggerService.
 // The channel logger sends log messages through a channel, allowing for
 // asynchronous processing of log messages via OnMessage subscribers.
-// It inherits timestamp, correlation ID, and icon settings from the LoggerService.
+// It inherits timestamp, correlation ID, and icon settings from the LoggerService,
+// and subscribers only get the messages passing the service log level.
+// An optional buffer size sets the channel buffer of every subscription made
+// with Subscribe or OnMessage, 100 by default, once a buffer is full further
+// messages are dropped for that subscriber, see SubscribeBlocking to avoid it.
 //
 // Example:
 //
 //	service := log.New()
-//	service.AddChannelLogger()
+//	service.AddChannelLogger(1000)
 //	service.OnMessage(func(msg LogMessage) {
 //	    fmt.Printf("Received: %s\n", msg)
 //	})
 //	service.Info("Hello from channel!")
-func (l *LoggerService) AddChannelLogger() {
+func (l *LoggerService) AddChannelLogger(bufferSize ...int) {
 	channelLogger := &ChannelLogger{
 		useTimestamp:      l.UseTimestamp,
 		userCorrelationId: l.useCorrelationId,
 		useIcons:          l.useIcons,
 	}
-	Register(channelLogger)
+	if len(bufferSize) > 0 {
+		channelLogger.bufferSize = bufferSize[0]
+	}
+	l.register(channelLogger)
+
+	if len(bufferSize) > 0 {
+		for _, logger := range l.getLoggers() {
+			if cl, ok := logger.(*ChannelLogger); ok {
+				cl.SetBufferSize(bufferSize[0])
+			}
+		}
+	}
+}
+
+// AddLogger adds a custom Logger implementation to the LoggerService.
+// This is the extension point for loggers implemented outside this package.
+// The logger is used as given, so call its Init first if the implementation
+// requires it, and it is configured with the current timestamp, correlation ID,
+// and icon settings.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddLogger(myLogger.Init())
+//	service.Info("Hello from my logger!")
+func (l *LoggerService) AddLogger(logger Logger) {
+	l.applyOptions(logger)
+
+	l.loggersMutex.Lock()
+	defer l.loggersMutex.Unlock()
+	l.Loggers = append(l.Loggers, logger)
 }
 
 // WithDebug sets the log level to Debug, enabling all log messages
@@ -76,7 +121,7 @@ func (l *LoggerService) AddChannelLogger() {
 //	service.Debug("This will be logged")
 //	service.Trace("This won't be logged")
 func (l *LoggerService) WithDebug() *LoggerService {
-	l.LogLevel = Debug
+	l.setLevel(Debug)
 	return l
 }
 
@@ -90,7 +135,7 @@ func (l *LoggerService) WithDebug() *LoggerService {
 //	service.Debug("This will be logged")
 //	service.Trace("This will also be logged")
 func (l *LoggerService) WithTrace() *LoggerService {
-	l.LogLevel = Trace
+	l.setLevel(Trace)
 	return l
 }
 
@@ -105,10 +150,134 @@ func (l *LoggerService) WithTrace() *LoggerService {
 //	service.Warn("This will be logged")
 //	service.Error("This will be logged")
 func (l *LoggerService) WithWarning() *LoggerService {
-	l.LogLevel = Warning
+	l.setLevel(Warning)
+	return l
+}
+
+// Quiet is the preset for a --quiet flag, it sets the log level to Error so
+// only Error, Exception and Fatal messages are logged. Warnings and the
+// pseudo-levels success, notice, command and disabled are suppressed unless
+// set as always on with WithAlwaysOn.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New()
+//	if quiet {
+//	    service.Quiet()
+//	}
+//	service.Warn("This won't be logged")
+//	service.Error("This will be logged")
+func (l *LoggerService) Quiet() *LoggerService {
+	l.setLevel(Error)
 	return l
 }
 
+// SetLevelFromString sets the log level from its name, see ParseLevel, such
+// as the value of a --log-level flag. An unknown name returns an error and
+// leaves the level unchanged.
+//
+// Example:
+//
+//	service := log.New()
+//	if err := service.SetLevelFromString("debug"); err != nil {
+//	    service.Warn("ignoring log level: %v", err)
+//	}
+func (l *LoggerService) SetLevelFromString(s string) error {
+	level, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+
+	l.setLevel(level)
+	return nil
+}
+
+// Verbose is the preset for a --verbose flag, it sets the log level to Debug
+// so every message except Trace is logged.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New()
+//	if verbose {
+//	    service.Verbose()
+//	}
+//	service.Debug("This will be logged")
+//	service.Trace("This won't be logged")
+func (l *LoggerService) Verbose() *LoggerService {
+	l.setLevel(Debug)
+	return l
+}
+
+// VeryVerbose is the preset for a -vv flag, it sets the log level to Trace so
+// every message is logged.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New()
+//	if veryVerbose {
+//	    service.VeryVerbose()
+//	}
+//	service.Trace("This will be logged")
+func (l *LoggerService) VeryVerbose() *LoggerService {
+	l.setLevel(Trace)
+	return l
+}
+
+// WithAlwaysOn classifies the pseudo-levels "success", "notice", "command" and
+// "disabled" as always on, so they are logged whatever the log level, or back
+// to level-gated when alwaysOn is false. By default they are all gated at Info.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithWarning().WithAlwaysOn(true, "command", "success")
+//	service.Command("git push")
+//	service.Info("Not logged at Warning level")
+//	// Output: git push
+func (l *LoggerService) WithAlwaysOn(alwaysOn bool, pseudoLevels ...string) *LoggerService {
+	if l.alwaysOn == nil {
+		l.alwaysOn = make(map[string]bool)
+	}
+	for _, pseudoLevel := range pseudoLevels {
+		l.alwaysOn[strings.ToLower(pseudoLevel)] = alwaysOn
+	}
+	return l
+}
+
+// SetSemanticLevel sets the level the pseudo-level "success", "notice", "command"
+// or "disabled" is gated at, so for example notices are still logged at Warning.
+// By default they are all gated at Info, WithAlwaysOn takes precedence.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithWarning()
+//	service.SetSemanticLevel("notice", log.Warning).SetSemanticLevel("disabled", log.Debug)
+//	service.Notice("Maintenance window starts at 22:00")
+//	service.Disabled("Not logged below Debug level")
+//	// Output: Maintenance window starts at 22:00
+func (l *LoggerService) SetSemanticLevel(name string, level Level) *LoggerService {
+	if l.semanticLevels == nil {
+		l.semanticLevels = make(map[string]Level)
+	}
+	l.semanticLevels[strings.ToLower(name)] = level
+	return l
+}
+
+// pseudoLevelEnabled reports whether a pseudo-level message should be logged,
+// either because it is always on or because the log level is at or above its
+// semantic level, Info unless set with SetSemanticLevel
+func (l *LoggerService) pseudoLevelEnabled(pseudoLevel string) bool {
+	level, ok := l.semanticLevels[pseudoLevel]
+	if !ok {
+		level = Info
+	}
+	return l.alwaysOn[pseudoLevel] || l.level() >= level
+}
+
 // WithTimestamp enables timestamp prefixing for all log messages.
 // Returns the LoggerService for method chaining.
 //
@@ -119,11 +288,43 @@ func (l *LoggerService) WithWarning() *LoggerService {
 //	service.Info("Hello")
 //	// Output: [2024-03-20T10:00:00Z] info: Hello
 func (l *LoggerService) WithTimestamp() *LoggerService {
-	for _, logger := range l.Loggers {
+	for _, logger := range l.getLoggers() {
+		logger.UseTimestamp(true)
+		if ul, ok := logger.(uptimeLogger); ok {
+			ul.UseUptimeTimestamp(time.Time{})
+		}
+	}
+
+	l.UseTimestamp = true
+	l.useUptime = false
+	return l
+}
+
+// WithUptimeTimestamps enables timestamp prefixing using the elapsed time since
+// the service was created instead of the wall-clock time. This is an alternative
+// to WithTimestamp, useful for short-lived command line tools.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New()
+//	service.WithUptimeTimestamps()
+//	service.Info("Hello")
+//	// Output: +0.123s info: Hello
+func (l *LoggerService) WithUptimeTimestamps() *LoggerService {
+	if l.startedAt.IsZero() {
+		l.startedAt = nowFunc()
+	}
+
+	for _, logger := range l.getLoggers() {
 		logger.UseTimestamp(true)
+		if ul, ok := logger.(uptimeLogger); ok {
+			ul.UseUptimeTimestamp(l.startedAt)
+		}
 	}
 
 	l.UseTimestamp = true
+	l.useUptime = true
 	return l
 }
 
@@ -140,7 +341,7 @@ func (l *LoggerService) WithTimestamp() *LoggerService {
 func (l *LoggerService) ToggleTimestamp() *LoggerService {
 	l.UseTimestamp = !l.UseTimestamp
 
-	for _, logger := range l.Loggers {
+	for _, logger := range l.getLoggers() {
 		logger.UseTimestamp(l.UseTimestamp)
 	}
 
@@ -158,7 +359,7 @@ func (l *LoggerService) ToggleTimestamp() *LoggerService {
 //	service.EnableTimestamp(false)
 //	service.Info("Without timestamp")
 func (l *LoggerService) EnableTimestamp(value bool) *LoggerService {
-	for _, logger := range l.Loggers {
+	for _, logger := range l.getLoggers() {
 		logger.UseTimestamp(value)
 	}
 
@@ -180,12 +381,110 @@ func (l *LoggerService) EnableTimestamp(value bool) *LoggerService {
 //	// Output: [req-123] info: Processing request
 func (l *LoggerService) WithCorrelationId() *LoggerService {
 	l.useCorrelationId = true
-	for _, logger := range l.Loggers {
+	for _, logger := range l.getLoggers() {
 		logger.UseCorrelationId(true)
 	}
 	return l
 }
 
+// WithCorrelationFallback sets the correlation ID used when a message has none,
+// from its context, RotateCorrelation or the CORRELATION_ID environment variable,
+// so every line keeps a correlation column when correlation IDs are displayed.
+// By default no ID is printed in that case, an empty value restores it.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithCorrelationId().WithCorrelationFallback("-")
+//	service.Info("Processing request")
+//	// Output: [-] info: Processing request
+func (l *LoggerService) WithCorrelationFallback(value string) *LoggerService {
+	l.correlationMutex.Lock()
+	defer l.correlationMutex.Unlock()
+
+	l.correlationFallback = value
+	return l
+}
+
+// SetCorrelationEnv sets the environment variable the correlation ID is read
+// from, for the service and every logger, such as TRACE_ID where the id is not
+// exported as CORRELATION_ID. An empty name restores CORRELATION_ID.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithCorrelationId().SetCorrelationEnv("TRACE_ID")
+//	os.Setenv("TRACE_ID", "trace-42")
+//	service.Info("Processing request")
+//	// Output: [trace-42] info: Processing request
+func (l *LoggerService) SetCorrelationEnv(name string) *LoggerService {
+	l.correlationEnv = name
+	for _, logger := range l.getLoggers() {
+		if cl, ok := logger.(correlationEnvLogger); ok {
+			cl.SetCorrelationEnv(name)
+		}
+	}
+	return l
+}
+
+// WithSchemaVersion stamps a schema version into every structured message,
+// such as the LogMessage delivered to channel subscribers, so downstream parsers
+// can handle format changes. An empty version uses LogMessageSchemaVersion.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New()
+//	service.WithSchemaVersion("2")
+//	service.OnMessage("json", func(msg LogMessage) {
+//	    data, _ := json.Marshal(msg)
+//	    fmt.Println(string(data))
+//	})
+//	service.Info("Hello")
+//	// Output: {"level":"info","message":"Hello",...,"schema_version":"2"}
+func (l *LoggerService) WithSchemaVersion(version string) *LoggerService {
+	if version == "" {
+		version = LogMessageSchemaVersion
+	}
+
+	l.schemaVersion = version
+	for _, logger := range l.getLoggers() {
+		if sl, ok := logger.(schemaVersionLogger); ok {
+			sl.SetSchemaVersion(version)
+		}
+	}
+	return l
+}
+
+// WithColorScheme overrides the colors used by the command line loggers for
+// the given levels, levels missing from the scheme keep their default color.
+// The scheme is also applied to the command line loggers added afterwards.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithColorScheme(map[log.Level]log.ColorCode{
+//	    log.Info:    log.Black,
+//	    log.Warning: log.Magenta,
+//	})
+//	service.Warn("Disk usage at %d%%", 90)
+//	// Output: \x1b[35mDisk usage at 90%\x1b[0m
+func (l *LoggerService) WithColorScheme(scheme map[Level]ColorCode) *LoggerService {
+	l.colorScheme = make(map[Level]ColorCode, len(scheme))
+	for level, code := range scheme {
+		l.colorScheme[level] = code
+	}
+
+	for _, logger := range l.getLoggers() {
+		if cl, ok := logger.(colorSchemeLogger); ok {
+			for level, code := range scheme {
+				cl.SetLevelColor(level, code)
+			}
+		}
+	}
+	return l
+}
+
 // WithIcons enables icon display in log messages.
 // Icons provide visual indicators for different types of log messages.
 // Returns the LoggerService for method chaining.
@@ -199,12 +498,40 @@ func (l *LoggerService) WithCorrelationId() *LoggerService {
 //	service.Success("Complete")    // Output: 👍 success: Complete
 func (l *LoggerService) WithIcons() *LoggerService {
 	l.useIcons = true
-	for _, logger := range l.Loggers {
+	for _, logger := range l.getLoggers() {
 		logger.UseIcons(true)
 	}
 	return l
 }
 
+// WouldLog reports whether a message at the given level would actually be
+// emitted by at least one logger. The level must pass the service log level and
+// at least one registered logger must accept it, loggers can drop messages on
+// their own, e.g. a channel logger without subscribers or a file logger without
+// a file. Loggers that do not report this are assumed to emit every message.
+// Use it to guard expensive work done only for logging.
+//
+// Example:
+//
+//	service := log.New()
+//	if service.WouldLog(log.Debug) {
+//	    service.Debug("state: %s", expensiveDump())
+//	}
+func (l *LoggerService) WouldLog(level Level) bool {
+	if l.level() < level {
+		return false
+	}
+
+	for _, logger := range l.getLoggers() {
+		checker, ok := logger.(levelChecker)
+		if !ok || checker.WouldLog(level) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Log logs a message with the specified level and format.
 // This is a low-level logging function that allows direct control of the log level.
 //
@@ -214,7 +541,12 @@ func (l *LoggerService) WithIcons() *LoggerService {
 //	service.Log("Processing item %d", log.Info, 42)
 //	// Output: info: Processing item 42
 func (l *LoggerService) Log(format string, level Level, words ...interface{}) {
-	for _, logger := range l.Loggers {
+	if !l.passesFilters(level, format, words) {
+		return
+	}
+
+	format = l.messagePrefix() + l.callerPrefix() + format
+	for _, logger := range l.getLoggers() {
 		logger.Log(format, level, words...)
 	}
 }
@@ -228,7 +560,12 @@ func (l *LoggerService) Log(format string, level Level, words ...interface{}) {
 //	service.LogIcon("🌟", "Special event %s", log.Info, "occurred")
 //	// Output: 🌟 info: Special event occurred
 func (l *LoggerService) LogIcon(icon LoggerIcon, format string, level Level, words ...interface{}) {
-	for _, logger := range l.Loggers {
+	if !l.passesFilters(level, format, words) {
+		return
+	}
+
+	format = l.messagePrefix() + l.callerPrefix() + format
+	for _, logger := range l.getLoggers() {
 		logger.LogIcon(icon, format, level, words...)
 	}
 }
@@ -236,6 +573,8 @@ func (l *LoggerService) LogIcon(icon LoggerIcon, format string, level Level, wor
 // LogHighlight logs a message with highlighted words using the specified color.
 // The color is applied to the interpolated values, not the format string.
 // This is useful for emphasizing important parts of log messages.
+// 256-color and truecolor highlights are made with Color256 and TrueColor, the
+// command line logger falls back to the nearest color the terminal supports.
 //
 // Example:
 //
@@ -248,8 +587,16 @@ func (l *LoggerService) LogIcon(icon LoggerIcon, format string, level Level, wor
 //	service.HighlightColor = strcolor.Red
 //	service.LogHighlight("Warning: %s", log.Warning, "Critical state")
 //	// Output: warn: Warning: Critical state (in red)
+//
+//	// With a truecolor orange:
+//	service.HighlightColor = strcolor.ColorCode(log.TrueColor(255, 128, 0))
 func (l *LoggerService) LogHighlight(format string, level Level, words ...interface{}) {
-	for _, logger := range l.Loggers {
+	if !l.passesFilters(level, format, words) {
+		return
+	}
+
+	format = l.messagePrefix() + l.callerPrefix() + format
+	for _, logger := range l.getLoggers() {
 		logger.LogHighlight(format, level, l.HighlightColor, words...)
 	}
 }
@@ -263,11 +610,43 @@ func (l *LoggerService) LogHighlight(format string, level Level, words ...interf
 //	service.Info("Server started on port %d", 8080)
 //	// Output: info: Server started on port 8080
 func (l *LoggerService) Info(format string, words ...interface{}) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.Info(format, words...)
-		}
-	}
+	l.InfoCtx(context.Background(), format, words...)
+}
+
+// Print logs the words at info level, joined like fmt.Sprint does, with
+// spaces between operands when neither is a string. No format string is
+// needed, which eases porting code from the standard library log package.
+//
+// Example:
+//
+//	service := log.New()
+//	service.Print("Server started on port ", 8080)
+//	// Output: info: Server started on port 8080
+func (l *LoggerService) Print(words ...interface{}) {
+	l.Info(escapeVerbs(fmt.Sprint(words...)))
+}
+
+// Printf logs a formatted message at info level, like Info.
+//
+// Example:
+//
+//	service := log.New()
+//	service.Printf("Server started on port %d", 8080)
+//	// Output: info: Server started on port 8080
+func (l *LoggerService) Printf(format string, words ...interface{}) {
+	l.Info(format, words...)
+}
+
+// Println logs the words at info level, joined like fmt.Sprintln does, with
+// a space between every operand. The trailing newline is dropped.
+//
+// Example:
+//
+//	service := log.New()
+//	service.Println("Server started on port", 8080)
+//	// Output: info: Server started on port 8080
+func (l *LoggerService) Println(words ...interface{}) {
+	l.Info(escapeVerbs(strings.TrimSuffix(fmt.Sprintln(words...), "\n")))
 }
 
 // Success logs a success message with a thumbs-up icon.
@@ -279,11 +658,7 @@ func (l *LoggerService) Info(format string, words ...interface{}) {
 //	service.Success("Operation completed: %s", "backup")
 //	// Output: 👍 success: Operation completed: backup
 func (l *LoggerService) Success(format string, words ...interface{}) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.Success(format, words...)
-		}
-	}
+	l.SuccessCtx(context.Background(), format, words...)
 }
 
 // Warn logs a warning message with a warning icon.
@@ -295,10 +670,85 @@ func (l *LoggerService) Success(format string, words ...interface{}) {
 //	service.Warn("Disk usage high: %d%%", 90)
 //	// Output: ⚠ warn: Disk usage high: 90%
 func (l *LoggerService) Warn(format string, words ...interface{}) {
-	if l.LogLevel >= Warning {
-		for _, logger := range l.Loggers {
-			logger.Warn(format, words...)
-		}
+	l.WarnCtx(context.Background(), format, words...)
+}
+
+// exitFunc ends the process, tests replace it to observe the exit code
+var exitFunc = os.Exit
+
+// TaskSuccess logs a task progress message at the success level, isComplete
+// marks the message ending the task, the command line and file loggers append
+// a [done] marker to it. Loggers without task support log it as a success
+// message. Messages are only logged if the success pseudo-level is
+// enabled, at Info or higher by default, see SetSemanticLevel. Task messages
+// are counted in Stats, deduped and sampled like the other messages.
+// With WithExitOnTaskComplete the service is closed and the process exits
+// with code 0 after a completed task.
+//
+// Example:
+//
+//	service := log.New()
+//	service.TaskSuccess("Uploaded %d of %d files", false, 5, 10)
+//	service.TaskSuccess("Uploaded %d files", true, 10)
+//	// Output: Uploaded 5 of 10 files
+//	// Output: Uploaded 10 files [done]
+func (l *LoggerService) TaskSuccess(format string, isComplete bool, words ...interface{}) {
+	if l.pseudoLevelEnabled("success") {
+		ctx := context.WithValue(context.Background(), taskContextKey, isComplete)
+		l.logCtx(ctx, IconThumbsUp, "success", func(logger Logger, format string, words ...interface{}) { logger.Success(format, words...) }, format, words...)
+	}
+
+	if isComplete && l.exitOnComplete {
+		l.Close()
+		exitFunc(0)
+	}
+}
+
+// WithExitOnTaskComplete makes TaskSuccess close the service and exit the
+// process with code 0 once a task completes, for CI steps ending on their last
+// task. It is disabled by default, a completed task is only marked as such.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithExitOnTaskComplete(true)
+//	service.TaskSuccess("Deployment finished", true)
+//	// The process exits with code 0
+func (l *LoggerService) WithExitOnTaskComplete(value bool) *LoggerService {
+	l.exitOnComplete = value
+	return l
+}
+
+// TaskWarn logs a task progress message at the warning level. Loggers without
+// task support log it as a warning message. Messages are only logged if the
+// service's log level is Warning or higher.
+//
+// Example:
+//
+//	service := log.New()
+//	service.TaskWarn("Skipped %d files", 2)
+//	// Output: Skipped 2 files
+func (l *LoggerService) TaskWarn(format string, words ...interface{}) {
+	if l.level() >= Warning {
+		ctx := context.WithValue(context.Background(), taskContextKey, false)
+		l.logCtx(ctx, IconWarning, "warn", func(logger Logger, format string, words ...interface{}) { logger.Warn(format, words...) }, format, words...)
+	}
+}
+
+// TaskError logs a task progress message at the error level, isComplete marks
+// the message ending the task. Loggers without task support log it as an
+// error message. Messages are only logged if the service's log level is Error
+// or higher.
+//
+// Example:
+//
+//	service := log.New()
+//	service.TaskError("Upload failed after %d files", true, 3)
+//	// Output: Upload failed after 3 files
+func (l *LoggerService) TaskError(format string, isComplete bool, words ...interface{}) {
+	if l.level() >= Error {
+		ctx := context.WithValue(context.Background(), taskContextKey, isComplete)
+		l.logCtx(ctx, IconRevolvingLight, "error", func(logger Logger, format string, words ...interface{}) { logger.Error(format, words...) }, format, words...)
 	}
 }
 
@@ -311,11 +761,7 @@ func (l *LoggerService) Warn(format string, words ...interface{}) {
 //	service.Command("Executing: %s", "git pull")
 //	// Output: 🔧 command: Executing: git pull
 func (l *LoggerService) Command(format string, words ...interface{}) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.Command(format, words...)
-		}
-	}
+	l.CommandCtx(context.Background(), format, words...)
 }
 
 // Disabled logs a disabled feature message with a black square icon.
@@ -327,11 +773,7 @@ func (l *LoggerService) Command(format string, words ...interface{}) {
 //	service.Disabled("Feature %s is disabled", "beta-testing")
 //	// Output: ⬛ disabled: Feature beta-testing is disabled
 func (l *LoggerService) Disabled(format string, words ...interface{}) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.Disabled(format, words...)
-		}
-	}
+	l.DisabledCtx(context.Background(), format, words...)
 }
 
 // Notice logs a notice message with a flag icon.
@@ -343,11 +785,7 @@ func (l *LoggerService) Disabled(format string, words ...interface{}) {
 //	service.Notice("Maintenance scheduled for %s", "tomorrow")
 //	// Output: 🚩 notice: Maintenance scheduled for tomorrow
 func (l *LoggerService) Notice(format string, words ...interface{}) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.Notice(format, words...)
-		}
-	}
+	l.NoticeCtx(context.Background(), format, words...)
 }
 
 // Debug logs a debug message with a fire icon.
@@ -359,11 +797,7 @@ func (l *LoggerService) Notice(format string, words ...interface{}) {
 //	service.Debug("Variable x = %d", 42)
 //	// Output: 🔥 debug: Variable x = 42
 func (l *LoggerService) Debug(format string, words ...interface{}) {
-	if l.LogLevel >= Debug {
-		for _, logger := range l.Loggers {
-			logger.Debug(format, words...)
-		}
-	}
+	l.DebugCtx(context.Background(), format, words...)
 }
 
 // Trace logs a trace message with a bulb icon.
@@ -381,11 +815,7 @@ func (l *LoggerService) Debug(format string, words ...interface{}) {
 //	service.Trace("Variable state: %+v", myVar)
 //	// Output: [2024-03-20T10:00:00Z] 💡 trace: Variable state: {Field:value}
 func (l *LoggerService) Trace(format string, words ...interface{}) {
-	if l.LogLevel >= Trace {
-		for _, logger := range l.Loggers {
-			logger.Debug(format, words...)
-		}
-	}
+	l.TraceCtx(context.Background(), format, words...)
 }
 
 // Error logs an error message with a revolving light icon.
@@ -397,11 +827,7 @@ func (l *LoggerService) Trace(format string, words ...interface{}) {
 //	service.Error("Failed to connect: %s", "timeout")
 //	// Output: 🚨 error: Failed to connect: timeout
 func (l *LoggerService) Error(format string, words ...interface{}) {
-	if l.LogLevel >= Error {
-		for _, logger := range l.Loggers {
-			logger.Error(format, words...)
-		}
-	}
+	l.ErrorCtx(context.Background(), format, words...)
 }
 
 // LogError logs an error object directly.
@@ -414,12 +840,8 @@ func (l *LoggerService) Error(format string, words ...interface{}) {
 //	service.LogError(err)
 //	// Output: error: connection failed
 func (l *LoggerService) LogError(message error) {
-	if l.LogLevel >= Error {
-		if message != nil {
-			for _, logger := range l.Loggers {
-				logger.Error(message.Error())
-			}
-		}
+	if message != nil {
+		l.ErrorCtx(context.Background(), message.Error())
 	}
 }
 
@@ -433,11 +855,7 @@ func (l *LoggerService) LogError(message error) {
 //	service.Exception(err, "Failed to load config from %s", "config.json")
 //	// Output: error: Failed to load config from config.json, err not found
 func (l *LoggerService) Exception(err error, format string, words ...interface{}) {
-	if l.LogLevel >= Error {
-		for _, logger := range l.Loggers {
-			logger.Exception(err, format, words...)
-		}
-	}
+	l.ExceptionCtx(context.Background(), err, format, words...)
 }
 
 // Fatal logs a fatal error message with a revolving light icon.
@@ -449,15 +867,13 @@ func (l *LoggerService) Exception(err error, format string, words ...interface{}
 //	service.Fatal("System failure: %s", "out of memory")
 //	// Output: 🚨 error: System failure: out of memory
 func (l *LoggerService) Fatal(format string, words ...interface{}) {
-	if l.LogLevel >= Error {
-		for _, logger := range l.Loggers {
-			logger.Fatal(format, words...)
-		}
-	}
+	l.FatalCtx(context.Background(), format, words...)
 }
 
 // FatalError logs an error message and then panics if the error is not nil.
 // This should be used for unrecoverable errors that require immediate shutdown.
+// The loggers are flushed before the panic, see Flush, so the message
+// explaining the crash is not lost while the process unwinds.
 //
 // Example:
 //
@@ -466,9 +882,13 @@ func (l *LoggerService) Fatal(format string, words ...interface{}) {
 //	// This will log the error and then panic:
 //	service.FatalError(err, "System crashed: %s", "unrecoverable state")
 func (l *LoggerService) FatalError(e error, format string, words ...interface{}) {
-	for _, logger := range l.Loggers {
-		logger.Error(format, words...)
+	if l.passesFilters(Error, format, words) {
+		format = l.messagePrefix() + l.callerPrefix() + format + escapeVerbs(l.stackTrace())
+		for _, logger := range l.getLoggers() {
+			logger.Error(format, words...)
+		}
 	}
+	l.Flush()
 
 	if e != nil {
 		panic(e)
@@ -514,6 +934,34 @@ func (l *LoggerService) GetRequestPrefix(r *http.Request, logUrl bool) string {
 	return msg
 }
 
+// ForRequest returns a LogEntry bound to the HTTP request, every message logged
+// through it carries the method and path fields and, when the request has an
+// X-Request-Id header, the request_id field and the request id as correlation id.
+// The entry derives from the request context, so a correlation id already stored
+// there is used when the header is missing.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//	    reqLog := service.ForRequest(r)
+//	    reqLog.Info("Loading user")
+//	    // Output: [req-123] Loading user method=GET path=/api/users request_id=req-123
+//	}
+func (l *LoggerService) ForRequest(r *http.Request) *LogEntry {
+	ctx := r.Context()
+	fields := map[string]any{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	}
+	if requestId := r.Header.Get("X-Request-Id"); requestId != "" {
+		fields["request_id"] = requestId
+		ctx = ContextWithCorrelationId(ctx, requestId)
+	}
+
+	entry := &LogEntry{service: l, ctx: ctx}
+	return entry.WithFields(fields)
+}
+
 // OnMessage registers a callback function to receive log messages from the channel logger.
 // The callback will be executed asynchronously for each log message.
 // Returns a subscription ID that can be used to unsubscribe later.
@@ -538,7 +986,7 @@ func (l *LoggerService) GetRequestPrefix(r *http.Request, logUrl bool) string {
 func (l *LoggerService) OnMessage(id string, callback func(LogMessage)) string {
 	// Find the channel logger instance
 	var channelLogger *ChannelLogger
-	for _, logger := range l.Loggers {
+	for _, logger := range l.getLoggers() {
 		if cl, ok := logger.(*ChannelLogger); ok {
 			channelLogger = cl
 			break
@@ -562,6 +1010,63 @@ func (l *LoggerService) OnMessage(id string, callback func(LogMessage)) string {
 	return subID
 }
 
+// OnMessageWithTimeout registers a message handler like OnMessage, each call
+// of the callback is given the timeout to return. When it takes longer a
+// warning is logged through the loggers other than the channel logger, so it
+// never reaches the handlers, and the next message is handled while the slow
+// call finishes in the background.
+// Returns an empty string if no channel logger is configured.
+//
+// Example:
+//
+//	service := log.New()
+//	service.OnMessageWithTimeout("ship", 100*time.Millisecond, func(msg LogMessage) {
+//	    shipToCollector(msg)
+//	})
+//	// Output when shipping hangs: message handler ship took longer than 100ms
+func (l *LoggerService) OnMessageWithTimeout(id string, timeout time.Duration, callback func(LogMessage)) string {
+	return l.OnMessage(id, func(msg LogMessage) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			callback(msg)
+		}()
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+			for _, logger := range l.getLoggers() {
+				if _, ok := logger.(*ChannelLogger); !ok {
+					logger.Warn("message handler %s took longer than %s", id, timeout)
+				}
+			}
+		}
+	})
+}
+
+// OnMessageJSON registers a handler writing every message to w as a line of
+// JSON, using the LogMessage schema, and returns the subscription ID.
+// Returns an empty string if no channel logger is configured.
+//
+// Example:
+//
+//	service := log.New()
+//	subID := service.OnMessageJSON("json-out", os.Stderr)
+//	service.Info("Test message")
+//	// Output on stderr: {"level":"info","message":"Test message","timestamp":"2024-03-20T10:00:00Z",...}
+//	service.RemoveMessageHandler(subID)
+func (l *LoggerService) OnMessageJSON(id string, w io.Writer) string {
+	return l.OnMessage(id, func(msg LogMessage) {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return
+		}
+		w.Write(append(data, '\n'))
+	})
+}
+
 // RemoveMessageHandler unsubscribes a message handler using its subscription ID.
 // Returns true if the handler was successfully removed, false if the handler wasn't found
 // or if no channel logger is configured.
@@ -582,10 +1087,308 @@ func (l *LoggerService) OnMessage(id string, callback func(LogMessage)) string {
 //	    fmt.Println("Failed to remove message handler")
 //	}
 func (l *LoggerService) RemoveMessageHandler(subscriptionID string) bool {
-	for _, logger := range l.Loggers {
+	for _, logger := range l.getLoggers() {
 		if cl, ok := logger.(*ChannelLogger); ok {
 			return cl.Unsubscribe(subscriptionID)
 		}
 	}
 	return false
 }
+
+// ChannelSubscribers returns the IDs of the subscriptions of the channel logger,
+// such as the message handlers added with OnMessage. Returns nil if no channel
+// logger is configured. Use it to spot handlers that were never removed.
+//
+// Example:
+//
+//	service := log.New()
+//	service.OnMessage("tail", func(msg LogMessage) {})
+//	fmt.Println(service.ChannelSubscribers())
+//	// Output: [sub_tail]
+func (l *LoggerService) ChannelSubscribers() []string {
+	for _, logger := range l.getLoggers() {
+		if cl, ok := logger.(*ChannelLogger); ok {
+			return cl.SubscriberIDs()
+		}
+	}
+	return nil
+}
+
+// DrainChannels drains every channel logger before shutdown, new messages are
+// no longer sent to the subscribers, which get up to timeout to read what is
+// left in their buffers before their channels are closed. Returns false when
+// messages were still unread once the timeout elapsed.
+//
+// Example:
+//
+//	service := log.New()
+//	service.OnMessage("forwarder", forward)
+//	defer service.Close()
+//	defer service.DrainChannels(2 * time.Second)
+func (l *LoggerService) DrainChannels(timeout time.Duration) bool {
+	drained := true
+	for _, logger := range l.getLoggers() {
+		if cl, ok := logger.(*ChannelLogger); ok {
+			if !cl.Drain(timeout) {
+				drained = false
+			}
+		}
+	}
+	return drained
+}
+
+// WithDedup collapses identical messages, same level and text, logged within
+// the window. DedupFirst only logs the first one, DedupFirstAndLast also logs
+// the last one with the number of suppressed repeats once the window elapses.
+// Pending repeats are logged when the service is closed.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithDedup(time.Minute, log.DedupFirstAndLast)
+//	for i := 0; i < 100; i++ {
+//	    service.Error("connection refused")
+//	}
+//	// Output: connection refused
+//	// one minute later
+//	// Output: connection refused (repeated 99 times)
+func (l *LoggerService) WithDedup(window time.Duration, mode DedupMode) *LoggerService {
+	if l.dedup != nil {
+		l.dedup.flush()
+	}
+	l.dedup = newDeduplicator(window, mode)
+	return l
+}
+
+// WithDedupStackFrames keys the dedup of error messages on the unformatted
+// message and the top frames of the caller stack instead of the formatted text,
+// so the same failure from the same place is collapsed even when its arguments
+// vary, while the same message from another call site is kept. Zero frames
+// restores the default key. It only applies once WithDedup is set.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithDedup(time.Minute, log.DedupFirst).WithDedupStackFrames(3)
+//	for _, id := range []string{"a1", "b2", "c3"} {
+//	    service.Error("payment %s failed", id)
+//	}
+//	// Output: payment a1 failed
+func (l *LoggerService) WithDedupStackFrames(frames int) *LoggerService {
+	l.dedupFrames = frames
+	return l
+}
+
+// AddFilter adds a predicate run on every message before it reaches any
+// logger, with the message level and its formatted text. A message is only
+// logged when every filter returns true.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddFilter(func(level log.Level, message string) bool {
+//	    return !strings.Contains(message, "healthcheck")
+//	})
+//	service.Info("GET /healthcheck 200")
+//	// Nothing is logged
+func (l *LoggerService) AddFilter(fn func(level Level, message string) bool) *LoggerService {
+	l.filters = append(l.filters, fn)
+	return l
+}
+
+// WithBuildInfo attaches the build version, commit and build time to every
+// message, as the version, commit and built_at fields of structured messages
+// and as key=value pairs appended to text lines. Empty values are left out.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithBuildInfo("1.4.0", "a1b2c3d", "2024-03-20T10:00:00Z")
+//	service.Info("Server started")
+//	// Output: Server started built_at=2024-03-20T10:00:00Z commit=a1b2c3d version=1.4.0
+func (l *LoggerService) WithBuildInfo(version, commit, builtAt string) *LoggerService {
+	buildInfo := make(map[string]any)
+	for key, value := range map[string]string{"version": version, "commit": commit, "built_at": builtAt} {
+		if value != "" {
+			buildInfo[key] = value
+		}
+	}
+
+	l.buildInfo = buildInfo
+	return l
+}
+
+// WithSource names the component logging through the service, structured
+// messages such as the LogMessage delivered to channel subscribers carry it in
+// their logger field so they can be filtered by component.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithSource("auth")
+//	service.OnMessage("json", func(msg LogMessage) {
+//	    data, _ := json.Marshal(msg)
+//	    fmt.Println(string(data))
+//	})
+//	service.Info("User logged in")
+//	// Output: {"level":"info","message":"User logged in",...,"logger":"auth"}
+func (l *LoggerService) WithSource(name string) *LoggerService {
+	l.source = name
+	return l
+}
+
+// WithPrefix returns a Clone of the service that prepends the prefix and a
+// space to every message, to tag the messages of a component. The prefix comes
+// after the timestamp and correlation id added by the loggers and before the
+// message. Prefixes of a service that already has one are nested.
+//
+// Example:
+//
+//	service := log.New().WithTimestamp().WithCorrelationId()
+//	db := service.WithPrefix("[db]")
+//	db.Info("connected to %s", "primary")
+//	// Output: 2024-03-20T10:00:00Z [req-123] [db] connected to primary
+//	db.WithPrefix("[pool]").Warn("exhausted")
+//	// Output: 2024-03-20T10:00:01Z [req-123] [db] [pool] exhausted
+func (l *LoggerService) WithPrefix(prefix string) *LoggerService {
+	clone := l.Clone()
+	if l.prefix != "" && prefix != "" {
+		prefix = l.prefix + " " + prefix
+	}
+	clone.prefix = prefix
+	return clone
+}
+
+// messagePrefix returns the prefix to prepend to a format string, or an empty
+// string when the service has no prefix
+func (l *LoggerService) messagePrefix() string {
+	if l.prefix == "" {
+		return ""
+	}
+	return escapeVerbs(l.prefix) + " "
+}
+
+// WithSummaryOnClose makes Close log a final summary line with the number of
+// messages logged per level and the time since the service started.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithSummaryOnClose()
+//	service.Info("Processing batch")
+//	service.Warn("Skipped a record")
+//	service.Close()
+//	// Output: Completed: 1 info, 1 warn, 0 error in 2m0s
+func (l *LoggerService) WithSummaryOnClose() *LoggerService {
+	l.summaryOnClose = true
+	return l
+}
+
+// Flush flushes every logger that implements Flusher, such as the file and
+// webhook loggers, so the messages logged so far are not lost if the process
+// exits. Pending dedup repeats and sampled counts are logged first and errors
+// returned by the loggers are joined together.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddFileLogger("app.log")
+//	service.Error("Unrecoverable state, shutting down")
+//	if err := service.Flush(); err != nil {
+//		fmt.Println(err)
+//	}
+//	os.Exit(1)
+func (l *LoggerService) Flush() error {
+	if l.dedup != nil {
+		l.dedup.flush()
+	}
+	if l.sampler != nil {
+		l.sampler.flush()
+	}
+
+	var errs []error
+	for _, logger := range l.getLoggers() {
+		flusher, ok := logger.(Flusher)
+		if !ok {
+			continue
+		}
+		if err := flusher.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SetOutput points every logger writing to an io.Writer, the command line,
+// mirror, file, writer and NDJSON loggers, at w. Loggers with their own
+// transport, such as the channel, webhook or syslog loggers, are left as they
+// are. Useful to capture the whole output in tests.
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	service := log.New()
+//	service.AddFileLogger("app.log")
+//	service.SetOutput(&buf)
+//	service.Info("Hello")
+//	// buf: Hello (twice, from the command line and file loggers)
+func (l *LoggerService) SetOutput(w io.Writer) {
+	for _, logger := range l.getLoggers() {
+		if wl, ok := logger.(writerSettable); ok {
+			wl.SetWriter(w)
+		}
+	}
+}
+
+// Close closes every logger that implements a Close method, such as the file
+// and channel loggers, and removes all loggers from the service. Errors returned
+// by the loggers are joined together. Pending dedup repeats and sampled counts
+// are logged first and when WithSummaryOnClose is set the summary line is
+// logged before the loggers are closed.
+// After Close the service has no loggers, so when called on the global logger
+// nothing is logged until New() is called again. On a Clone the loggers shared
+// with the service it was cloned from are left open.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddFileLogger("app.log")
+//	defer service.Close()
+func (l *LoggerService) Close() error {
+	if l.dedup != nil {
+		l.dedup.flush()
+	}
+	if l.sampler != nil {
+		l.sampler.flush()
+	}
+
+	// The loggers are detached under the lock and closed without it, so a
+	// logger calling back into the service while closing does not deadlock
+	l.loggersMutex.Lock()
+	loggers := l.Loggers
+	silencedLoggers := l.silencedLoggers
+	l.Loggers = []Logger{}
+	l.silencedLoggers = nil
+	l.loggersMutex.Unlock()
+
+	if l.summaryOnClose {
+		summary := l.summary()
+		for _, logger := range loggers {
+			logger.Info("%s", summary)
+		}
+	}
+
+	var errs []error
+	for _, logger := range append(append([]Logger{}, loggers...), silencedLoggers...) {
+		if l.isShared(logger) {
+			continue
+		}
+		if err := closeLogger(logger); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -1,11 +1,16 @@
 package log
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -196,6 +201,58 @@ func TestLoggerService_GetRequestPrefix(t *testing.T) {
 	}
 }
 
+func TestLoggerService_ForRequest(t *testing.T) {
+	t.Run("stamps request fields", func(t *testing.T) {
+		channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		_, ch := channelLogger.Subscribe("request", func(LogMessage) bool { return true })
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{channelLogger, mockLogger},
+		}
+
+		req := httptest.NewRequest("GET", "/api/users", nil)
+		req.Header.Set("X-Request-Id", "req-123")
+		reqLog := service.ForRequest(req)
+		reqLog.Info("loading user %d", 42)
+
+		msg := <-ch
+		assert.Equal(t, "loading user 42", msg.Message)
+		assert.Equal(t, map[string]any{"method": "GET", "path": "/api/users", "request_id": "req-123"}, msg.Fields)
+		assert.Equal(t, "loading user 42 method=GET path=/api/users request_id=req-123", mockLogger.LastPrintedMessage.Message)
+	})
+
+	t.Run("request id as correlation id", func(t *testing.T) {
+		var output bytes.Buffer
+		logger := (&NDJSONLogger{writer: &output}).Init().(*NDJSONLogger)
+		logger.UseCorrelationId(true)
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{logger},
+		}
+
+		req := httptest.NewRequest("POST", "/login", nil)
+		req.Header.Set("X-Request-Id", "req-456")
+		service.ForRequest(req).WithField("user_id", 7).Warn("slow login")
+
+		assert.Contains(t, output.String(), `"message":"[req-456] slow login"`)
+		assert.Contains(t, output.String(), `"user_id":7`)
+	})
+
+	t.Run("without request id", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+
+		req := httptest.NewRequest("DELETE", "/api/users/1", nil)
+		service.ForRequest(req).Info("deleting user")
+
+		assert.Equal(t, "deleting user method=DELETE path=/api/users/1", mockLogger.LastPrintedMessage.Message)
+	})
+}
+
 func TestLoggerService_AddLoggers(t *testing.T) {
 	service := New()
 
@@ -646,8 +703,8 @@ func TestLoggerService_Trace(t *testing.T) {
 			expectedMsg := fmt.Sprintf(tt.format, tt.args...)
 			if tt.shouldLog {
 				assert.Equal(t, expectedMsg, mockLogger.LastPrintedMessage.Message)
-				assert.Equal(t, "debug", mockLogger.LastPrintedMessage.Level) // Note: Trace uses Debug internally
-				assert.Equal(t, string(IconFire), mockLogger.LastPrintedMessage.Icon)
+				assert.Equal(t, "trace", mockLogger.LastPrintedMessage.Level)
+				assert.Equal(t, string(IconBulb), mockLogger.LastPrintedMessage.Icon)
 			} else {
 				assert.Empty(t, mockLogger.LastPrintedMessage.Message)
 			}
@@ -840,3 +897,1133 @@ func cleanup() {
 		}
 	}
 }
+
+func TestLoggerService_WithUptimeTimestamps(t *testing.T) {
+	startedAt := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	now := startedAt
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	var output bytes.Buffer
+	cmdLogger := &CmdLogger{writer: &output}
+	service := &LoggerService{
+		LogLevel:  Info,
+		Loggers:   []Logger{cmdLogger},
+		startedAt: startedAt,
+	}
+
+	service.WithUptimeTimestamps()
+	assert.True(t, service.UseTimestamp)
+	assert.True(t, cmdLogger.useTimestamp)
+
+	now = startedAt.Add(1234 * time.Millisecond)
+	service.Info("first")
+	now = startedAt.Add(2 * time.Minute)
+	service.Info("second")
+
+	assert.Equal(t, "\x1b[0m+1.234s first\x1b[0m\n\x1b[0m+120.000s second\x1b[0m\n", output.String())
+
+	t.Run("WithTimestamp restores wall-clock timestamps", func(t *testing.T) {
+		output.Reset()
+		service.WithTimestamp()
+		service.Info("wall clock")
+
+		assert.Equal(t, "\x1b[0m2024-03-20T10:02:00Z wall clock\x1b[0m\n", output.String())
+	})
+}
+
+func TestLoggerService_RemoveLogger(t *testing.T) {
+	t.Run("RemoveLogger closes and removes the file logger", func(t *testing.T) {
+		service := New()
+		service.AddFileLogger(filepath.Join(t.TempDir(), "remove.log"))
+		initialCount := len(service.Loggers)
+
+		fileLogger, ok := service.Loggers[initialCount-1].(*FileLogger)
+		assert.True(t, ok)
+
+		assert.True(t, RemoveLogger[*FileLogger]())
+		assert.Equal(t, initialCount-1, len(service.Loggers))
+		_, err := fileLogger.writer.Write([]byte("after remove"))
+		assert.Error(t, err)
+
+		assert.False(t, RemoveLogger[*FileLogger]())
+	})
+
+	t.Run("RemoveLoggerByType matches full and short type names", func(t *testing.T) {
+		service := New()
+		initialCount := len(service.Loggers)
+
+		assert.True(t, service.RemoveLoggerByType("cmdlogger"))
+		assert.Equal(t, initialCount-1, len(service.Loggers))
+
+		assert.True(t, service.RemoveLoggerByType("*log.ChannelLogger"))
+		assert.Equal(t, initialCount-2, len(service.Loggers))
+
+		assert.False(t, service.RemoveLoggerByType("FileLogger"))
+	})
+}
+
+func TestGetLogger(t *testing.T) {
+	service := New()
+	defer service.Close()
+
+	channelLogger, ok := GetLogger[*ChannelLogger]()
+	assert.True(t, ok)
+	assert.Same(t, service.Loggers[1], channelLogger)
+
+	fileLogger, ok := GetLogger[*FileLogger]()
+	assert.False(t, ok)
+	assert.Nil(t, fileLogger)
+
+	service.AddFileLogger(filepath.Join(t.TempDir(), "get.log"))
+	fileLogger, ok = GetLogger[*FileLogger]()
+	assert.True(t, ok)
+	assert.NotNil(t, fileLogger)
+}
+
+func TestNewIsolated(t *testing.T) {
+	global := New()
+	defer global.Close()
+
+	first := NewIsolated()
+	second := NewIsolated()
+	assert.Same(t, global, Get())
+	assert.NotSame(t, first, second)
+
+	assert.Equal(t, Info, first.LogLevel)
+	assert.Len(t, first.Loggers, 2)
+	assert.IsType(t, &CmdLogger{}, first.Loggers[0])
+	assert.IsType(t, &ChannelLogger{}, first.Loggers[1])
+
+	// Loggers are added to the receiver only
+	first.AddFileLogger(filepath.Join(t.TempDir(), "isolated.log"))
+	defer first.Close()
+	assert.Len(t, first.Loggers, 3)
+	assert.Len(t, second.Loggers, 2)
+	assert.Len(t, global.Loggers, 2)
+
+	_, ok := GetLogger[*FileLogger]()
+	assert.False(t, ok)
+}
+
+func TestLoggerService_AddLoggersOnReceiver(t *testing.T) {
+	global := New()
+	defer global.Close()
+
+	service := &LoggerService{LogLevel: Info}
+	service.AddCmdLogger()
+	service.AddChannelLogger()
+	service.AddChannelLogger()
+	service.AddFileLogger(filepath.Join(t.TempDir(), "receiver.log"))
+	defer service.Close()
+
+	assert.Len(t, service.Loggers, 3)
+	assert.IsType(t, &CmdLogger{}, service.Loggers[0])
+	assert.IsType(t, &ChannelLogger{}, service.Loggers[1])
+	assert.IsType(t, &FileLogger{}, service.Loggers[2])
+	assert.Len(t, global.Loggers, 2)
+
+	// The global Register still adds to the global logger
+	Register(&MockLogger{})
+	assert.Len(t, global.Loggers, 3)
+	assert.Len(t, service.Loggers, 3)
+}
+
+func TestLoggerService_MultipleFileLoggers(t *testing.T) {
+	dir := t.TempDir()
+	auditFile := filepath.Join(dir, "audit.log")
+	debugFile := filepath.Join(dir, "debug.log")
+
+	service := &LoggerService{LogLevel: Info}
+	service.AddFileLogger(auditFile)
+	service.AddFileLogger(debugFile)
+	service.AddFileLogger(auditFile)
+	assert.Len(t, service.Loggers, 2)
+
+	service.Info("user %s signed in", "bob")
+	assert.NoError(t, service.Close())
+
+	for _, filename := range []string{auditFile, debugFile} {
+		content, err := os.ReadFile(filename)
+		assert.NoError(t, err)
+		assert.Equal(t, "user bob signed in\n", string(content))
+	}
+}
+
+// customLogger is a Logger implemented outside the package loggers, it reuses
+// MockLogger for the interface and records the info calls it receives
+type customLogger struct {
+	MockLogger
+	infoCalls []string
+}
+
+func (l *customLogger) Info(format string, words ...interface{}) {
+	l.infoCalls = append(l.infoCalls, fmt.Sprintf(format, words...))
+}
+
+func TestLoggerService_AddLogger(t *testing.T) {
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{},
+	}
+	service.WithTimestamp().WithIcons()
+
+	custom := &customLogger{}
+	service.AddLogger(custom)
+
+	assert.Equal(t, 1, len(service.Loggers))
+	assert.True(t, custom.useTimestamp)
+	assert.True(t, custom.useIcons)
+	assert.False(t, custom.userCorrelationId)
+
+	service.Info("hello %s", "custom")
+	service.Warn("careful")
+
+	assert.Equal(t, []string{"hello custom"}, custom.infoCalls)
+	assert.Equal(t, "careful", custom.LastPrintedMessage.Message)
+}
+
+// closeErrorLogger is a logger whose Close method returns an error
+type closeErrorLogger struct {
+	MockLogger
+	closed bool
+}
+
+func (l *closeErrorLogger) Close() error {
+	l.closed = true
+	return errors.New("close failed")
+}
+
+func TestLoggerService_Close(t *testing.T) {
+	t.Run("closes all closable loggers", func(t *testing.T) {
+		fileLogger := FileLogger{filename: filepath.Join(t.TempDir(), "close.log")}.Init().(*FileLogger)
+		channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		_, ch := channelLogger.Subscribe("close", func(LogMessage) bool { return true })
+		service := &LoggerService{
+			Loggers: []Logger{fileLogger, channelLogger, &MockLogger{}},
+		}
+
+		assert.NoError(t, service.Close())
+		assert.Empty(t, service.Loggers)
+
+		_, err := fileLogger.writer.Write([]byte("after close"))
+		assert.Error(t, err)
+		_, ok := <-ch
+		assert.False(t, ok)
+	})
+
+	t.Run("aggregates close errors", func(t *testing.T) {
+		failing := &closeErrorLogger{}
+		service := &LoggerService{
+			Loggers: []Logger{failing, &MockLogger{}},
+		}
+
+		err := service.Close()
+		assert.EqualError(t, err, "close failed")
+		assert.True(t, failing.closed)
+		assert.Empty(t, service.Loggers)
+	})
+
+	t.Run("loggers may call back into the service", func(t *testing.T) {
+		service := &LoggerService{LogLevel: Info}
+		reentrant := &reentrantLogger{service: service}
+		service.AddLogger(reentrant)
+		service.WithSummaryOnClose()
+
+		done := make(chan error)
+		go func() { done <- service.Close() }()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("Close deadlocked")
+		}
+		assert.Contains(t, reentrant.LastPrintedMessage.Message, "Completed:")
+	})
+}
+
+// reentrantLogger is a logger calling back into its service when it is closed
+type reentrantLogger struct {
+	MockLogger
+	service *LoggerService
+}
+
+func (l *reentrantLogger) Close() error {
+	l.service.Info("closing")
+	l.service.AddLogger(&MockLogger{})
+	return nil
+}
+
+func TestLoggerService_WithSummaryOnClose(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel:  Info,
+		Loggers:   []Logger{mockLogger},
+		startedAt: now,
+	}
+	service.WithSummaryOnClose()
+
+	service.Info("one")
+	service.Info("two")
+	service.Warn("careful")
+	service.Success("done")
+	service.Error("failed")
+	service.Debug("not logged at info level")
+
+	assert.Equal(t, map[string]int64{"info": 2, "warn": 1, "success": 1, "error": 1}, service.Stats())
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, service.Close())
+	assert.Equal(t, "Completed: 2 info, 1 success, 1 warn, 1 error in 2m0s", mockLogger.LastPrintedMessage.Message)
+	assert.Equal(t, "info", mockLogger.LastPrintedMessage.Level)
+}
+
+func TestLoggerService_ConcurrentRegisterAndLog(t *testing.T) {
+	service := New()
+	service.RemoveLoggerByType("CmdLogger")
+	defer service.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				service.Info("goroutine %d message %d", i, j)
+			}
+		}(i)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		service.AddFileLogger(filepath.Join(t.TempDir(), "concurrent.log"))
+		service.AddLogger((&ChannelLogger{}).Init())
+		service.RemoveLoggerByType("ChannelLogger")
+	}()
+
+	wg.Wait()
+	loggers := service.getLoggers()
+	assert.Equal(t, 1, len(loggers))
+	assert.IsType(t, &FileLogger{}, loggers[0])
+}
+
+func TestLoggerService_WouldLog(t *testing.T) {
+	channelWithSubscriber := func() *ChannelLogger {
+		logger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		logger.Subscribe("would-log", func(LogMessage) bool { return true })
+		return logger
+	}
+
+	tests := []struct {
+		name     string
+		logLevel Level
+		loggers  []Logger
+		level    Level
+		expected bool
+	}{
+		{name: "below the service level", logLevel: Info, loggers: []Logger{&MockLogger{}}, level: Debug, expected: false},
+		{name: "at the service level", logLevel: Info, loggers: []Logger{&MockLogger{}}, level: Info, expected: true},
+		{name: "above the service level", logLevel: Debug, loggers: []Logger{&MockLogger{}}, level: Error, expected: true},
+		{name: "no loggers", logLevel: Trace, loggers: []Logger{}, level: Error, expected: false},
+		{name: "channel logger without subscribers", logLevel: Trace, loggers: []Logger{(&ChannelLogger{}).Init()}, level: Info, expected: false},
+		{name: "channel logger with subscribers", logLevel: Trace, loggers: []Logger{channelWithSubscriber()}, level: Info, expected: true},
+		{name: "file logger without file", logLevel: Trace, loggers: []Logger{FileLogger{}.Init()}, level: Info, expected: false},
+		{
+			name:     "one accepting logger is enough",
+			logLevel: Info,
+			loggers:  []Logger{(&ChannelLogger{}).Init(), FileLogger{}.Init(), &MockLogger{}},
+			level:    Warning,
+			expected: true,
+		},
+		{
+			name:     "accepting logger but service level too low",
+			logLevel: Warning,
+			loggers:  []Logger{channelWithSubscriber()},
+			level:    Info,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &LoggerService{
+				LogLevel: tt.logLevel,
+				Loggers:  tt.loggers,
+			}
+
+			assert.Equal(t, tt.expected, service.WouldLog(tt.level))
+		})
+	}
+}
+
+func TestLoggerService_WithDedup(t *testing.T) {
+	t.Run("first and last", func(t *testing.T) {
+		channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+		_, ch := channelLogger.Subscribe("dedup", func(LogMessage) bool { return true })
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{channelLogger},
+		}
+		service.WithDedup(20*time.Millisecond, DedupFirstAndLast)
+
+		for i := 0; i < 5; i++ {
+			service.Error("connection refused to %s", "db")
+		}
+
+		first := <-ch
+		assert.Equal(t, "connection refused to db", first.Message)
+
+		// The last message arrives once the window elapses
+		select {
+		case last := <-ch:
+			assert.Equal(t, "connection refused to db (repeated 4 times)", last.Message)
+			assert.Equal(t, "error", last.Level)
+		case <-time.After(time.Second):
+			t.Fatal("Expected the last message of the burst")
+		}
+
+		// A new burst starts once the window elapsed
+		service.Error("connection refused to %s", "db")
+		assert.Equal(t, "connection refused to db", (<-ch).Message)
+		assert.Empty(t, ch)
+	})
+
+	t.Run("first only", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+		service.WithDedup(time.Minute, DedupFirst)
+
+		for i := 0; i < 5; i++ {
+			service.Error("connection refused")
+		}
+		service.Warn("connection refused")
+		assert.NoError(t, service.Close())
+
+		assert.Len(t, mockLogger.PrintedMessages, 2)
+		assert.Equal(t, "error", mockLogger.PrintedMessages[0].Level)
+		assert.Equal(t, "warn", mockLogger.PrintedMessages[1].Level)
+	})
+
+	t.Run("close logs pending repeats", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+		service.WithDedup(time.Minute, DedupFirstAndLast)
+
+		service.Error("disk full")
+		service.Error("disk full")
+		assert.NoError(t, service.Close())
+
+		assert.Len(t, mockLogger.PrintedMessages, 2)
+		assert.Equal(t, "disk full (repeated 1 times)", mockLogger.PrintedMessages[1].Message)
+	})
+}
+
+func TestLoggerService_RepeatFlag(t *testing.T) {
+	var output bytes.Buffer
+	channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	_, ch := channelLogger.Subscribe("repeat", func(LogMessage) bool { return true })
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{channelLogger},
+	}
+	service.AddWriterLogger(&output, LogfmtEncoder{})
+	service.WithDedup(time.Minute, DedupFirstAndLast)
+
+	for i := 0; i < 4; i++ {
+		service.Error("connection refused")
+	}
+	assert.NoError(t, service.Close())
+
+	first := <-ch
+	assert.Equal(t, 0, first.Repeat)
+	last := <-ch
+	assert.Equal(t, 3, last.Repeat)
+	assert.Equal(t, "connection refused (repeated 3 times)", last.Message)
+	assert.Contains(t, output.String(), "repeat=3\n")
+}
+
+func TestLoggerService_AddFilter(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Debug,
+		Loggers:  []Logger{mockLogger},
+	}
+	service.AddFilter(func(level Level, message string) bool {
+		return !strings.Contains(message, "healthcheck")
+	})
+	service.AddFilter(func(level Level, message string) bool {
+		return level != Debug || strings.HasPrefix(message, "db")
+	})
+
+	service.Info("GET /%s 200", "healthcheck")
+	service.Info("GET /users 200")
+	service.Debug("cache hit")
+	service.Debug("db query took %dms", 12)
+	service.Error("healthcheck failed")
+
+	assert.Equal(t, []string{"GET /users 200", "db query took 12ms"}, mockMessages(mockLogger))
+	assert.Equal(t, int64(1), service.Stats()["info"])
+}
+
+func TestLoggerService_AddFilterLowLevelMethods(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+	service.AddFilter(func(level Level, message string) bool {
+		return !strings.Contains(message, "healthcheck")
+	})
+
+	service.Log("GET /%s 200", Info, "healthcheck")
+	service.LogIcon(IconInfo, "healthcheck icon", Info)
+	service.LogHighlight("healthcheck %s", Info, "highlight")
+	service.TaskSuccess("healthcheck task", false)
+	service.TaskWarn("healthcheck task warning")
+	service.TaskError("healthcheck task failed", true)
+	assert.Panics(t, func() { service.FatalError(errors.New("down"), "healthcheck fatal") })
+	service.Log("GET /users 200", Info)
+
+	assert.Equal(t, []string{"GET /users 200"}, mockMessages(mockLogger))
+}
+
+// failPayment logs a payment failure from a single call site
+//
+//go:noinline
+func failPayment(service *LoggerService, id string) {
+	service.Error("payment %s failed", id)
+}
+
+func TestLoggerService_WithDedupStackFrames(t *testing.T) {
+	t.Run("same call site collapses", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+		service.WithDedup(time.Minute, DedupFirst).WithDedupStackFrames(2)
+
+		for _, id := range []string{"a1", "b2", "c3"} {
+			failPayment(service, id)
+		}
+		assert.NoError(t, service.Close())
+
+		assert.Len(t, mockLogger.PrintedMessages, 1)
+		assert.Equal(t, "payment a1 failed", mockLogger.PrintedMessages[0].Message)
+	})
+
+	t.Run("different call sites are kept", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+		service.WithDedup(time.Minute, DedupFirst).WithDedupStackFrames(2)
+
+		service.Error("payment %s failed", "a1")
+		service.Error("payment %s failed", "a1")
+		assert.NoError(t, service.Close())
+
+		assert.Len(t, mockLogger.PrintedMessages, 2)
+	})
+
+	t.Run("identical errors from the same call site", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+		service.WithDedup(time.Minute, DedupFirstAndLast).WithDedupStackFrames(2)
+
+		for i := 0; i < 3; i++ {
+			failPayment(service, "a1")
+		}
+		assert.NoError(t, service.Close())
+
+		assert.Len(t, mockLogger.PrintedMessages, 2)
+		assert.Equal(t, "payment a1 failed (repeated 2 times)", mockLogger.PrintedMessages[1].Message)
+	})
+}
+
+func TestLoggerService_Tasks(t *testing.T) {
+	var output bytes.Buffer
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Warning,
+		Loggers:  []Logger{mockLogger, &NDJSONLogger{writer: &output}},
+	}
+
+	service.TaskSuccess("uploaded %d files", true, 10)
+	service.TaskWarn("skipped %d files", 2)
+	service.TaskError("upload failed", true)
+
+	assert.Len(t, mockLogger.PrintedMessages, 2)
+	assert.Equal(t, "warn", mockLogger.PrintedMessages[0].Level)
+	assert.Equal(t, "skipped 2 files", mockLogger.PrintedMessages[0].Message)
+	assert.Equal(t, "error", mockLogger.PrintedMessages[1].Level)
+
+	// Loggers without task support get the plain level message
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"message":"skipped 2 files"`)
+	assert.Contains(t, lines[1], `"level":"error"`)
+}
+
+func TestLoggerService_TasksOnChannel(t *testing.T) {
+	channel := (&ChannelLogger{}).Init().(*ChannelLogger)
+	service := &LoggerService{LogLevel: Info, Loggers: []Logger{channel}}
+	_, ch := channel.Subscribe("progress", func(msg LogMessage) bool { return msg.IsTask })
+
+	service.Info("not a task")
+	service.TaskSuccess("uploaded %d of %d files", false, 5, 10)
+	service.TaskSuccess("uploaded %d files", true, 10)
+
+	progress := <-ch
+	assert.Equal(t, "uploaded 5 of 10 files", progress.Message)
+	assert.False(t, progress.IsComplete)
+	complete := <-ch
+	assert.Equal(t, "uploaded 10 files", complete.Message)
+	assert.True(t, complete.IsComplete)
+	assert.Len(t, ch, 0)
+}
+
+func TestLoggerService_TasksBookkeeping(t *testing.T) {
+	t.Run("counted in stats and the close summary", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{LogLevel: Info, Loggers: []Logger{mockLogger}}
+		service.WithSummaryOnClose()
+
+		service.TaskSuccess("uploaded %d of %d files", false, 5, 10)
+		service.TaskSuccess("uploaded %d files", true, 10)
+		service.TaskWarn("skipped %d files", 2)
+		service.TaskError("upload failed", true)
+
+		assert.Equal(t, map[string]int64{"success": 2, "warn": 1, "error": 1}, service.Stats())
+		assert.NoError(t, service.Close())
+		summary := mockLogger.PrintedMessages[len(mockLogger.PrintedMessages)-1].Message
+		assert.Contains(t, summary, "2 success")
+	})
+
+	t.Run("deduped and sampled", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{LogLevel: Info, Loggers: []Logger{mockLogger}}
+		service.WithDedup(time.Minute, DedupFirst)
+
+		for i := 0; i < 3; i++ {
+			service.TaskWarn("retrying upload")
+		}
+		assert.Equal(t, []string{"retrying upload"}, mockMessages(mockLogger))
+
+		mockLogger.Clear()
+		sampled := &LoggerService{LogLevel: Info, Loggers: []Logger{mockLogger}}
+		sampled.WithSampling(2)
+		for i := 0; i < 3; i++ {
+			sampled.TaskSuccess("uploaded %d files", false, i)
+		}
+		assert.Equal(t, []string{
+			"uploaded 0 files",
+			"... 1 similar messages suppressed",
+			"uploaded 2 files",
+		}, mockMessages(mockLogger))
+	})
+}
+
+func TestLoggerService_WithExitOnTaskComplete(t *testing.T) {
+	originalExit := exitFunc
+	defer func() { exitFunc = originalExit }()
+
+	tests := []struct {
+		name       string
+		exit       bool
+		isComplete bool
+		wantExit   bool
+	}{
+		{name: "disabled by default", exit: false, isComplete: true, wantExit: false},
+		{name: "exits on complete", exit: true, isComplete: true, wantExit: true},
+		{name: "progress does not exit", exit: true, isComplete: false, wantExit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exitCode := -1
+			exitFunc = func(code int) { exitCode = code }
+
+			var output bytes.Buffer
+			mockLogger := &MockLogger{}
+			service := &LoggerService{
+				LogLevel: Info,
+				Loggers:  []Logger{mockLogger, &CmdLogger{writer: &output, noColors: true}},
+			}
+			service.WithExitOnTaskComplete(tt.exit)
+
+			service.TaskSuccess("deployment %s", tt.isComplete, "finished")
+
+			assert.Equal(t, "deployment finished", mockLogger.LastPrintedMessage.Message)
+			if tt.isComplete {
+				assert.Equal(t, "deployment finished [done]\n", output.String())
+			} else {
+				assert.Equal(t, "deployment finished\n", output.String())
+			}
+			if tt.wantExit {
+				assert.Equal(t, 0, exitCode)
+			} else {
+				assert.Equal(t, -1, exitCode)
+			}
+		})
+	}
+}
+
+func TestLoggerService_WithAlwaysOn(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Warning,
+		Loggers:  []Logger{mockLogger},
+	}
+
+	service.Command("gated by default")
+	assert.Empty(t, mockLogger.PrintedMessages)
+
+	service.WithAlwaysOn(true, "command")
+	service.Command("git %s", "push")
+	service.Success("still gated")
+	service.Info("not logged at warning")
+
+	assert.Len(t, mockLogger.PrintedMessages, 1)
+	assert.Equal(t, "git push", mockLogger.LastPrintedMessage.Message)
+	assert.Equal(t, "command", mockLogger.LastPrintedMessage.Level)
+
+	service.WithAlwaysOn(false, "command")
+	service.Command("gated again")
+	assert.Len(t, mockLogger.PrintedMessages, 1)
+}
+
+func TestLoggerService_SetSemanticLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		logLevel Level
+		levels   map[string]Level
+		expected []string
+	}{
+		{
+			name:     "defaults gate at info",
+			logLevel: Info,
+			expected: []string{"success", "command", "disabled", "notice"},
+		},
+		{
+			name:     "defaults hidden at warning",
+			logLevel: Warning,
+			expected: []string{},
+		},
+		{
+			name:     "notice raised to warning",
+			logLevel: Warning,
+			levels:   map[string]Level{"Notice": Warning},
+			expected: []string{"notice"},
+		},
+		{
+			name:     "disabled lowered to debug",
+			logLevel: Info,
+			levels:   map[string]Level{"disabled": Debug},
+			expected: []string{"success", "command", "notice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := &MockLogger{}
+			service := &LoggerService{
+				LogLevel: tt.logLevel,
+				Loggers:  []Logger{mockLogger},
+			}
+			for name, level := range tt.levels {
+				service.SetSemanticLevel(name, level)
+			}
+
+			service.Success("success")
+			service.Command("command")
+			service.Disabled("disabled")
+			service.Notice("notice")
+
+			messages := make([]string, 0)
+			for _, msg := range mockLogger.PrintedMessages {
+				messages = append(messages, msg.Message)
+			}
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestLoggerService_VerbosityPresets(t *testing.T) {
+	tests := []struct {
+		name     string
+		preset   func(l *LoggerService) *LoggerService
+		expected []string
+	}{
+		{
+			name:     "quiet logs errors only",
+			preset:   (*LoggerService).Quiet,
+			expected: []string{"error", "exception"},
+		},
+		{
+			name:     "verbose logs up to debug",
+			preset:   (*LoggerService).Verbose,
+			expected: []string{"error", "exception", "warn", "info", "success", "notice", "debug"},
+		},
+		{
+			name:     "very verbose logs everything",
+			preset:   (*LoggerService).VeryVerbose,
+			expected: []string{"error", "exception", "warn", "info", "success", "notice", "debug", "trace"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := &MockLogger{}
+			service := &LoggerService{
+				LogLevel: Info,
+				Loggers:  []Logger{mockLogger},
+			}
+			assert.Same(t, service, tt.preset(service))
+
+			service.Error("error")
+			service.Exception(errors.New("failed"), "exception")
+			service.Warn("warn")
+			service.Info("info")
+			service.Success("success")
+			service.Notice("notice")
+			service.Debug("debug")
+			service.Trace("trace")
+
+			messages := make([]string, 0)
+			for _, msg := range mockLogger.PrintedMessages {
+				messages = append(messages, strings.SplitN(msg.Message, ",", 2)[0])
+			}
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+// flushLogger is a MockLogger implementing Flusher, it counts the flushes and
+// returns err from each of them
+type flushLogger struct {
+	MockLogger
+	flushes int
+	err     error
+}
+
+func (l *flushLogger) Flush() error {
+	l.flushes++
+	return l.err
+}
+
+func TestLoggerService_Flush(t *testing.T) {
+	t.Run("flushes buffered file loggers", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "flush.log")
+		service := &LoggerService{LogLevel: Info}
+		service.AddFileLogger(logFile)
+		defer service.Close()
+		service.Loggers[0].(*FileLogger).UseBuffer(4096)
+
+		service.Error("about to exit")
+		content, err := os.ReadFile(logFile)
+		assert.NoError(t, err)
+		assert.Empty(t, string(content))
+
+		assert.NoError(t, service.Flush())
+		content, err = os.ReadFile(logFile)
+		assert.NoError(t, err)
+		assert.Equal(t, "about to exit\n", string(content))
+	})
+
+	t.Run("joins flush errors and skips other loggers", func(t *testing.T) {
+		first := &flushLogger{err: errors.New("disk full")}
+		second := &flushLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{first, &MockLogger{}, second},
+		}
+
+		err := service.Flush()
+		assert.EqualError(t, err, "disk full")
+		assert.Equal(t, 1, first.flushes)
+		assert.Equal(t, 1, second.flushes)
+	})
+}
+
+func TestLoggerService_FatalErrorFlushes(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "fatal.log")
+	service := &LoggerService{LogLevel: Info}
+	service.AddFileLogger(logFile)
+	defer service.Close()
+	service.Loggers[0].(*FileLogger).UseBuffer(4096)
+
+	err := errors.New("out of memory")
+	func() {
+		defer func() {
+			assert.Equal(t, err, recover())
+		}()
+		service.FatalError(err, "System crashed: %s", "unrecoverable state")
+	}()
+
+	content, readErr := os.ReadFile(logFile)
+	assert.NoError(t, readErr)
+	assert.Contains(t, string(content), "System crashed: unrecoverable state")
+}
+
+func TestLoggerService_Print(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      func(service *LoggerService)
+		expected string
+	}{
+		{
+			name:     "print joins like fmt.Sprint",
+			log:      func(service *LoggerService) { service.Print("port ", 8080, " ready") },
+			expected: "port 8080 ready",
+		},
+		{
+			name:     "print spaces non string operands",
+			log:      func(service *LoggerService) { service.Print(1, 2, "three") },
+			expected: "1 2three",
+		},
+		{
+			name:     "printf formats verbs",
+			log:      func(service *LoggerService) { service.Printf("port %d ready", 8080) },
+			expected: "port 8080 ready",
+		},
+		{
+			name:     "println spaces every operand",
+			log:      func(service *LoggerService) { service.Println("port", 8080, "ready") },
+			expected: "port 8080 ready",
+		},
+		{
+			name:     "percent signs are kept",
+			log:      func(service *LoggerService) { service.Println("disk at", "90%d") },
+			expected: "disk at 90%d",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := &MockLogger{}
+			service := &LoggerService{
+				LogLevel: Info,
+				Loggers:  []Logger{mockLogger},
+			}
+
+			tt.log(service)
+
+			assert.Len(t, mockLogger.PrintedMessages, 1)
+			assert.Equal(t, "info", mockLogger.PrintedMessages[0].Level)
+			assert.Equal(t, tt.expected, mockLogger.PrintedMessages[0].Message)
+		})
+	}
+}
+
+func TestLoggerService_WithColorScheme(t *testing.T) {
+	var output bytes.Buffer
+	existing := &CmdLogger{writer: &output}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{existing},
+	}
+
+	assert.Same(t, service, service.WithColorScheme(map[Level]ColorCode{Warning: Magenta}))
+	service.Warn("careful")
+	assert.Equal(t, "\x1b[35mcareful\x1b[0m\n", output.String())
+
+	// Loggers added afterwards get the scheme too
+	mirrorFile := filepath.Join(t.TempDir(), "mirror.log")
+	var console bytes.Buffer
+	service.Loggers = nil
+	service.register(&MirrorLogger{filename: mirrorFile, console: &console})
+	defer service.Close()
+	service.Warn("careful")
+	assert.Equal(t, "\x1b[35mcareful\x1b[0m\n", console.String())
+}
+
+func TestLoggerService_OnMessageJSON(t *testing.T) {
+	service := &LoggerService{LogLevel: Info}
+	service.AddChannelLogger()
+	defer service.Close()
+
+	var output syncBuffer
+	subID := service.OnMessageJSON("json", &output)
+	assert.NotEmpty(t, subID)
+
+	service.Info("first")
+	service.Warn("second %d", 2)
+
+	assert.Eventually(t, func() bool {
+		return strings.Count(output.String(), "\n") == 2
+	}, time.Second, 5*time.Millisecond)
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	expected := []struct{ level, message string }{{"info", "first"}, {"warn", "second 2"}}
+	for i, line := range lines {
+		var record map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		assert.Equal(t, expected[i].level, record["level"])
+		assert.Equal(t, expected[i].message, record["message"])
+		assert.Contains(t, record, "timestamp")
+		assert.Contains(t, record, "icon")
+		assert.Contains(t, record, "is_task")
+	}
+
+	assert.Empty(t, (&LoggerService{}).OnMessageJSON("json", &output))
+}
+
+func TestLoggerService_Clone(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+	service.WithBuildInfo("1.2.0", "abc123", "")
+
+	clone := service.Clone().WithDebug()
+	clone.prefix = "[auth]"
+	clone.AddFilter(func(level Level, message string) bool { return message != "dropped" })
+	clone.Debug("token %s refreshed", "t1")
+	clone.Info("dropped")
+	service.Debug("hidden")
+	service.Info("dropped")
+
+	messages := make([]string, 0)
+	for _, msg := range mockLogger.PrintedMessages {
+		messages = append(messages, msg.Message)
+	}
+	assert.Equal(t, []string{
+		"[auth] token t1 refreshed commit=abc123 version=1.2.0",
+		"dropped commit=abc123 version=1.2.0",
+	}, messages)
+	assert.Equal(t, Info, service.LogLevel)
+	assert.Empty(t, service.prefix)
+	assert.Len(t, service.filters, 0)
+	assert.Equal(t, service.buildInfo, clone.buildInfo)
+	assert.Equal(t, int64(1), clone.Stats()["debug"])
+	assert.Zero(t, service.Stats()["debug"])
+}
+
+func TestLoggerService_CloneLeavesSharedLoggers(t *testing.T) {
+	service := NewIsolated()
+	defer service.Close()
+	messages := make(chan LogMessage, 10)
+	service.OnMessage("parent", func(msg LogMessage) { messages <- msg })
+
+	scoped := service.WithPrefix("[c]")
+	scoped.WithWarning()
+	owned := &closeErrorLogger{}
+	scoped.AddLogger(owned)
+	assert.True(t, scoped.RemoveLoggerByType("ChannelLogger"))
+	assert.Error(t, scoped.Close())
+	assert.True(t, owned.closed)
+	service.Info("parent still logs")
+
+	select {
+	case msg := <-messages:
+		assert.Equal(t, "parent still logs", msg.Message)
+	case <-time.After(time.Second):
+		t.Fatal("the parent channel logger was closed by the clone")
+	}
+	assert.Empty(t, scoped.Loggers)
+}
+
+func TestLoggerService_WithPrefix(t *testing.T) {
+	var output bytes.Buffer
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{&CmdLogger{writer: &output, noColors: true}},
+	}
+	service.WithCorrelationId()
+	db := service.WithPrefix("[db] 100%")
+	ctx := context.WithValue(context.Background(), CorrelationIdKey, "req-1")
+
+	db.InfoCtx(ctx, "connected to %s", "primary")
+	db.Log("raw %d", Info, 1)
+	db.WithPrefix("[pool]").Warn("exhausted")
+	service.Info("untagged")
+	assert.Equal(t, "[req-1] [db] 100% connected to primary\n[db] 100% raw 1\n[db] 100% [pool] exhausted\nuntagged\n", output.String())
+	assert.Empty(t, service.prefix)
+}
+
+func TestLoggerService_ChannelSubscribers(t *testing.T) {
+	service := &LoggerService{LogLevel: Info}
+	assert.Nil(t, service.ChannelSubscribers())
+
+	service.AddChannelLogger()
+	defer service.Close()
+	assert.Empty(t, service.ChannelSubscribers())
+
+	subID := service.OnMessage("tail", func(LogMessage) {})
+	assert.Equal(t, []string{subID}, service.ChannelSubscribers())
+
+	service.RemoveMessageHandler(subID)
+	assert.Empty(t, service.ChannelSubscribers())
+}
+
+func TestLoggerService_OnMessageWithTimeout(t *testing.T) {
+	var output syncBuffer
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{&CmdLogger{writer: &output, noColors: true}},
+	}
+	service.AddChannelLogger()
+	defer service.Close()
+
+	release := make(chan struct{})
+	handled := make(chan string, 10)
+	service.OnMessageWithTimeout("slow", 20*time.Millisecond, func(msg LogMessage) {
+		if msg.Message == "hang" {
+			<-release
+		}
+		handled <- msg.Message
+	})
+
+	service.Info("hang")
+	service.Info("fast")
+
+	// The next message is handled while the slow call is still running
+	assert.Equal(t, "fast", <-handled)
+	close(release)
+	assert.Equal(t, "hang", <-handled)
+
+	assert.Equal(t, "hang\nfast\nmessage handler slow took longer than 20ms\n", output.String())
+}
+
+func TestLoggerService_SetOutput(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	var original bytes.Buffer
+	service := &LoggerService{LogLevel: Info}
+	service.AddLogger(CmdLogger{}.Init())
+	service.AddFileLogger(logFile)
+	service.AddNDJSONLogger(&original)
+	channel := (&ChannelLogger{}).Init()
+	service.AddLogger(channel)
+
+	var output syncBuffer
+	service.SetOutput(&output)
+	service.Info("hello")
+	assert.NoError(t, service.Close())
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Equal(t, []string{"hello", "hello"}, lines[:2])
+	assert.Contains(t, lines[2], `"message":"hello"`)
+	assert.Empty(t, original.String())
+
+	content, err := os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.Empty(t, string(content))
+}
@@ -3,7 +3,10 @@ package log
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	strcolor "github.com/cjlapao/common-go/strcolor"
 )
@@ -12,12 +15,46 @@ var globalLogger *LoggerService
 
 // Logger Default structure
 type LoggerService struct {
-	Loggers          []Logger
-	LogLevel         Level
-	HighlightColor   strcolor.ColorCode
-	UseTimestamp     bool
-	useIcons         bool
-	useCorrelationId bool
+	Loggers             []Logger
+	silencedLoggers     []Logger
+	sharedLoggers       []Logger
+	LogLevel            Level
+	HighlightColor      strcolor.ColorCode
+	UseTimestamp        bool
+	useIcons            bool
+	useCorrelationId    bool
+	useUptime           bool
+	startedAt           time.Time
+	schemaVersion       string
+	colorScheme         map[Level]ColorCode
+	correlationId       string
+	correlationFallback string
+	correlationEnv      string
+	summaryOnClose      bool
+	exitOnComplete      bool
+	counts              map[string]int64
+	dedup               *deduplicator
+	dedupFrames         int
+	sampler             *sampler
+	alwaysOn            map[string]bool
+	semanticLevels      map[string]Level
+	source              string
+	prefix              string
+	buildInfo           map[string]any
+	redactors           []Redactor
+	filters             []func(level Level, message string) bool
+	useCaller           bool
+	callerSkip          int
+	stackTraces         bool
+	unwrapErrors        bool
+	stackFilter         []string
+	levelScopes         []*levelScope
+	scopeBaseLevel      Level
+	correlationMutex    sync.RWMutex
+	loggersMutex        sync.RWMutex
+	statsMutex          sync.Mutex
+	scopeMutex          sync.Mutex
+	levelMutex          sync.RWMutex
 }
 
 // Get Creates a new Logger instance
@@ -30,53 +67,142 @@ func Get() *LoggerService {
 }
 
 func New() *LoggerService {
-	globalLogger = &LoggerService{
-		LogLevel:       Info,
-		HighlightColor: strcolor.BrightYellow,
-		Loggers:        []Logger{},
-	}
+	globalLogger = newService()
+	globalLogger.AddCmdLogger()
+	globalLogger.AddChannelLogger()
 
-	_logLevel := os.Getenv(LOG_LEVEL)
-	if _logLevel == "debug" {
-		globalLogger.LogLevel = Debug
-	}
+	return globalLogger
+}
 
-	if _logLevel == "trace" {
-		globalLogger.LogLevel = Trace
+// NewIsolated creates a LoggerService configured like New, with the command
+// line and channel loggers, without replacing the global logger returned by
+// Get, so several independent services can live in one process.
+//
+// Example:
+//
+//	tenantA := log.NewIsolated().WithSource("tenant-a")
+//	tenantB := log.NewIsolated().WithDebug()
+//	tenantA.Info("only on tenant A")
+func NewIsolated() *LoggerService {
+	service := newService()
+	service.AddCmdLogger()
+	service.AddChannelLogger()
+
+	return service
+}
+
+// Clone returns a service with a copy of the configuration sharing the loggers,
+// the clone can change its level, prefix, filters or other service settings
+// without affecting the service it was cloned from. Pending dedup repeats and
+// sampling are shared, the message counts start from zero. The settings stored
+// on the loggers themselves, such as WithTimestamp, WithIcons, WithCorrelationId
+// or SetOutput, change the shared loggers for both services. Close and
+// RemoveLogger on the clone leave the shared loggers open for the service it
+// was cloned from and only close the loggers added to the clone.
+//
+// Example:
+//
+//	service := log.New()
+//	auth := service.WithPrefix("[auth]").WithDebug()
+//	auth.Debug("token refreshed")
+//	// Output: [auth] token refreshed
+//	service.Debug("hidden, the service is still at info")
+func (l *LoggerService) Clone() *LoggerService {
+	l.correlationMutex.RLock()
+	correlationId := l.correlationId
+	correlationFallback := l.correlationFallback
+	l.correlationMutex.RUnlock()
+
+	loggers := l.getLoggers()
+	clone := &LoggerService{
+		Loggers:             loggers,
+		sharedLoggers:       append(append([]Logger{}, loggers...), l.sharedLoggers...),
+		LogLevel:            l.level(),
+		HighlightColor:      l.HighlightColor,
+		UseTimestamp:        l.UseTimestamp,
+		useIcons:            l.useIcons,
+		useCorrelationId:    l.useCorrelationId,
+		useUptime:           l.useUptime,
+		startedAt:           l.startedAt,
+		schemaVersion:       l.schemaVersion,
+		colorScheme:         copyMap(l.colorScheme),
+		correlationId:       correlationId,
+		correlationFallback: correlationFallback,
+		correlationEnv:      l.correlationEnv,
+		summaryOnClose:      l.summaryOnClose,
+		exitOnComplete:      l.exitOnComplete,
+		dedup:               l.dedup,
+		dedupFrames:         l.dedupFrames,
+		sampler:             l.sampler,
+		alwaysOn:            copyMap(l.alwaysOn),
+		semanticLevels:      copyMap(l.semanticLevels),
+		source:              l.source,
+		prefix:              l.prefix,
+		buildInfo:           copyMap(l.buildInfo),
+		redactors:           append([]Redactor{}, l.redactors...),
+		filters:             append([]func(level Level, message string) bool{}, l.filters...),
+		useCaller:           l.useCaller,
+		callerSkip:          l.callerSkip,
+		stackTraces:         l.stackTraces,
+		unwrapErrors:        l.unwrapErrors,
+		stackFilter:         append([]string{}, l.stackFilter...),
 	}
+	return clone
+}
 
-	globalLogger.AddCmdLogger()
-	globalLogger.AddChannelLogger()
+// copyMap returns a copy of the map, nil for a nil map
+func copyMap[K comparable, V any](values map[K]V) map[K]V {
+	if values == nil {
+		return nil
+	}
 
-	return globalLogger
+	result := make(map[K]V, len(values))
+	for key, value := range values {
+		result[key] = value
+	}
+	return result
 }
 
-func NewMockLogger() *LoggerService {
-	globalLogger = &LoggerService{
+// newService creates a service without loggers at the level set by the
+// LOG_LEVEL environment variable, Info by default or for an unknown level
+func newService() *LoggerService {
+	service := &LoggerService{
 		LogLevel:       Info,
 		HighlightColor: strcolor.BrightYellow,
 		Loggers:        []Logger{},
+		startedAt:      nowFunc(),
 	}
 
-	_logLevel := os.Getenv(LOG_LEVEL)
-	if _logLevel == "debug" {
-		globalLogger.LogLevel = Debug
+	if level, err := ParseLevel(os.Getenv(LOG_LEVEL)); err == nil {
+		service.LogLevel = level
 	}
 
-	if _logLevel == "trace" {
-		globalLogger.LogLevel = Trace
-	}
+	return service
+}
 
-	Register(&MockLogger{})
+func NewMockLogger() *LoggerService {
+	globalLogger = newService()
+	globalLogger.register(&MockLogger{})
 	return globalLogger
 }
 
+// Register adds the logger to the global logger, see Get, unless a logger of
+// the same type is already registered. File, mirror and webhook loggers are
+// only deduped when they share the same destination.
 func Register[T Logger](value T) {
-	l := Get()
+	Get().register(value)
+}
+
+// register initializes the logger and adds it to the service unless a logger
+// of the same type, and the same destination for keyed loggers, is already registered
+func (l *LoggerService) register(value Logger) {
+	l.loggersMutex.Lock()
+	defer l.loggersMutex.Unlock()
+
 	found := false
-	newType := fmt.Sprintf("%T", value)
+	newType := registrationType(value)
 	for _, logger := range l.Loggers {
-		xType := fmt.Sprintf("%T", logger)
+		xType := registrationType(logger)
 		if strings.EqualFold(newType, xType) {
 			found = true
 			break
@@ -85,18 +211,160 @@ func Register[T Logger](value T) {
 
 	if !found {
 		logger := value.Init()
-		logger.UseTimestamp(l.UseTimestamp)
-		logger.UseIcons(l.useIcons)
-		logger.UseCorrelationId(l.useCorrelationId)
+		l.applyOptions(logger)
 		l.Loggers = append(l.Loggers, logger)
 	}
 }
 
-func GetMockLogger() (*MockLogger, error) {
-	for _, logger := range globalLogger.Loggers {
-		if logger, ok := logger.(*MockLogger); ok {
-			return logger, nil
+// registrationType identifies the logger for the register dedupe, its type
+// followed by its key for keyed loggers
+func registrationType(logger Logger) string {
+	name := fmt.Sprintf("%T", logger)
+	if kl, ok := logger.(keyedLogger); ok {
+		name = name + "|" + kl.registrationKey()
+	}
+	return name
+}
+
+// getLoggers returns the registered loggers, the slice is replaced and never
+// modified in place by the writers so it can be ranged over without holding the lock
+func (l *LoggerService) getLoggers() []Logger {
+	l.loggersMutex.RLock()
+	defer l.loggersMutex.RUnlock()
+
+	return l.Loggers
+}
+
+// applyOptions configures a logger with the current service settings
+func (l *LoggerService) applyOptions(logger Logger) {
+	logger.UseTimestamp(l.UseTimestamp)
+	logger.UseIcons(l.useIcons)
+	logger.UseCorrelationId(l.useCorrelationId)
+	if ul, ok := logger.(uptimeLogger); ok && l.useUptime {
+		ul.UseUptimeTimestamp(l.startedAt)
+	}
+	if sl, ok := logger.(schemaVersionLogger); ok && l.schemaVersion != "" {
+		sl.SetSchemaVersion(l.schemaVersion)
+	}
+	if rl, ok := logger.(redactorLogger); ok && len(l.redactors) > 0 {
+		rl.SetRedactors(l.redactors)
+	}
+	if cl, ok := logger.(correlationEnvLogger); ok && l.correlationEnv != "" {
+		cl.SetCorrelationEnv(l.correlationEnv)
+	}
+	if cl, ok := logger.(colorSchemeLogger); ok {
+		for level, code := range l.colorScheme {
+			cl.SetLevelColor(level, code)
+		}
+	}
+}
+
+// RemoveLogger removes every logger of type T from the global logger, closing
+// it first when it implements a Close method. Returns true if any logger was removed.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddFileLogger("test.log")
+//	log.RemoveLogger[*log.FileLogger]()
+func RemoveLogger[T Logger]() bool {
+	return Get().removeLoggers(func(logger Logger) bool {
+		_, ok := logger.(T)
+		return ok
+	})
+}
+
+// RemoveLoggerByType removes every logger whose type name matches name, closing
+// it first when it implements a Close method. The name can be the full type name
+// as printed by %T (e.g. "*log.FileLogger") or just the type name ("FileLogger"),
+// compared case-insensitively. Returns true if any logger was removed.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddFileLogger("test.log")
+//	service.RemoveLoggerByType("FileLogger")
+func (l *LoggerService) RemoveLoggerByType(name string) bool {
+	return l.removeLoggers(func(logger Logger) bool {
+		xType := fmt.Sprintf("%T", logger)
+		shortType := xType[strings.LastIndex(xType, ".")+1:]
+		return strings.EqualFold(name, xType) || strings.EqualFold(name, shortType)
+	})
+}
+
+// removeLoggers closes and removes the loggers matching the predicate
+func (l *LoggerService) removeLoggers(match func(Logger) bool) bool {
+	l.loggersMutex.Lock()
+	defer l.loggersMutex.Unlock()
+
+	removed := false
+	loggers := make([]Logger, 0, len(l.Loggers))
+	for _, logger := range l.Loggers {
+		if match(logger) {
+			if !l.isShared(logger) {
+				_ = closeLogger(logger)
+			}
+			removed = true
+			continue
+		}
+		loggers = append(loggers, logger)
+	}
+
+	l.Loggers = loggers
+	return removed
+}
+
+// isShared reports whether the logger was shared with the service by Clone,
+// it is then left open for the service it was cloned from. Loggers whose type
+// can not be compared are taken as shared when the clone holds one of the type
+func (l *LoggerService) isShared(logger Logger) bool {
+	loggerType := reflect.TypeOf(logger)
+	for _, shared := range l.sharedLoggers {
+		if reflect.TypeOf(shared) != loggerType {
+			continue
 		}
+		if !loggerType.Comparable() || shared == logger {
+			return true
+		}
+	}
+	return false
+}
+
+// closeLogger closes the logger if it implements either io.Closer or a plain Close method
+func closeLogger(logger Logger) error {
+	switch c := logger.(type) {
+	case interface{ Close() error }:
+		return c.Close()
+	case interface{ Close() }:
+		c.Close()
+	}
+
+	return nil
+}
+
+// GetLogger returns the first logger of type T registered in the global logger,
+// so its specific methods can be called without keeping a reference to it.
+//
+// Example:
+//
+//	service := log.New()
+//	if channelLogger, ok := log.GetLogger[*log.ChannelLogger](); ok {
+//	    _, ch := channelLogger.Subscribe("audit", func(log.LogMessage) bool { return true })
+//	}
+func GetLogger[T Logger]() (T, bool) {
+	for _, logger := range Get().getLoggers() {
+		if logger, ok := logger.(T); ok {
+			return logger, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func GetMockLogger() (*MockLogger, error) {
+	if logger, ok := GetLogger[*MockLogger](); ok {
+		return logger, nil
 	}
 
 	return nil, fmt.Errorf("MockLogger not found")
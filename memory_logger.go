@@ -0,0 +1,257 @@
+package log
+
+import (
+	"sync"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+)
+
+// DefaultMemoryCapacity is the number of messages kept by a MemoryLogger
+// created without a capacity
+const DefaultMemoryCapacity = 1000
+
+// MemoryLogger in memory Logger implementation keeping the most recent
+// messages in a fixed size ring buffer, for crash dumps and post-mortem
+// debugging. It also keeps the messages below the service log level.
+type MemoryLogger struct {
+	useTimestamp      bool
+	userCorrelationId bool
+	useIcons          bool
+	correlationEnv    string
+	schemaVersion     string
+	capacity          int
+	messages          []LogMessage
+	next              int
+	full              bool
+	redactors         []Redactor
+	bufferMutex       sync.Mutex
+}
+
+func (l *MemoryLogger) Init() Logger {
+	capacity := l.capacity
+	if capacity < 1 {
+		capacity = DefaultMemoryCapacity
+	}
+
+	return &MemoryLogger{
+		useTimestamp:      false,
+		userCorrelationId: false,
+		useIcons:          false,
+		capacity:          capacity,
+		messages:          make([]LogMessage, capacity),
+	}
+}
+
+// AddMemoryLogger adds a logger keeping the last capacity messages in memory
+// to the LoggerService and returns it, a capacity below one keeps the default
+// of 1000 messages. The logger also keeps the messages below the service log
+// level, so a dump taken after a failure shows what led to it.
+//
+// Example:
+//
+//	service := log.New()
+//	memory := service.AddMemoryLogger(200)
+//	defer func() {
+//	    if r := recover(); r != nil {
+//	        for _, msg := range memory.Dump() {
+//	            fmt.Fprintln(os.Stderr, msg)
+//	        }
+//	        panic(r)
+//	    }
+//	}()
+func (l *LoggerService) AddMemoryLogger(capacity int) *MemoryLogger {
+	logger := (&MemoryLogger{capacity: capacity}).Init().(*MemoryLogger)
+	l.AddLogger(logger)
+	return logger
+}
+
+func (l *MemoryLogger) IsTimestampEnabled() bool {
+	return l.useTimestamp
+}
+
+func (l *MemoryLogger) UseTimestamp(value bool) {
+	l.useTimestamp = value
+}
+
+func (l *MemoryLogger) UseCorrelationId(value bool) {
+	l.userCorrelationId = value
+}
+
+func (l *MemoryLogger) UseIcons(value bool) {
+	l.useIcons = value
+}
+
+// SetCorrelationEnv sets the environment variable the correlation id is read
+// from, CORRELATION_ID when empty
+func (l *MemoryLogger) SetCorrelationEnv(name string) {
+	l.correlationEnv = name
+}
+
+// SetRedactors sets the redactors run on every message before it is written
+func (l *MemoryLogger) SetRedactors(redactors []Redactor) {
+	l.redactors = redactors
+}
+
+// SetSchemaVersion stamps the version into every written LogMessage, an empty
+// version leaves the field out
+func (l *MemoryLogger) SetSchemaVersion(version string) {
+	l.schemaVersion = version
+}
+
+// Log Log information message
+func (l *MemoryLogger) Log(format string, level Level, words ...interface{}) {
+	switch level {
+	case 0:
+		l.printMessage(format, "", "error", correlationIdFromEnv(l.correlationEnv), words...)
+	case 1:
+		l.printMessage(format, "", "warn", correlationIdFromEnv(l.correlationEnv), words...)
+	case 2:
+		l.printMessage(format, "", "info", correlationIdFromEnv(l.correlationEnv), words...)
+	case 3:
+		l.printMessage(format, "", "debug", correlationIdFromEnv(l.correlationEnv), words...)
+	case 4:
+		l.printMessage(format, "", "trace", correlationIdFromEnv(l.correlationEnv), words...)
+	}
+}
+
+// LogIcon Log information message
+func (l *MemoryLogger) LogIcon(icon LoggerIcon, format string, level Level, words ...interface{}) {
+	switch level {
+	case 0:
+		l.printMessage(format, icon, "error", correlationIdFromEnv(l.correlationEnv), words...)
+	case 1:
+		l.printMessage(format, icon, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+	case 2:
+		l.printMessage(format, icon, "info", correlationIdFromEnv(l.correlationEnv), words...)
+	case 3:
+		l.printMessage(format, icon, "debug", correlationIdFromEnv(l.correlationEnv), words...)
+	case 4:
+		l.printMessage(format, icon, "trace", correlationIdFromEnv(l.correlationEnv), words...)
+	}
+}
+
+// LogHighlight Log information message, the highlight color is dropped as
+// the message is kept as plain text
+func (l *MemoryLogger) LogHighlight(format string, level Level, highlightColor strcolor.ColorCode, words ...interface{}) {
+	l.Log(format, level, words...)
+}
+
+// Info log information message
+func (l *MemoryLogger) Info(format string, words ...interface{}) {
+	l.printMessage(format, IconInfo, "info", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Success log message
+func (l *MemoryLogger) Success(format string, words ...interface{}) {
+	l.printMessage(format, IconThumbsUp, "success", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Warn log message
+func (l *MemoryLogger) Warn(format string, words ...interface{}) {
+	l.printMessage(format, IconWarning, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Command log message
+func (l *MemoryLogger) Command(format string, words ...interface{}) {
+	l.printMessage(format, IconWrench, "command", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Disabled log message
+func (l *MemoryLogger) Disabled(format string, words ...interface{}) {
+	l.printMessage(format, IconBlackSquare, "disabled", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Notice log message
+func (l *MemoryLogger) Notice(format string, words ...interface{}) {
+	l.printMessage(format, IconFlag, "notice", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Debug log message
+func (l *MemoryLogger) Debug(format string, words ...interface{}) {
+	l.printMessage(format, IconFire, "debug", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Trace log message
+func (l *MemoryLogger) Trace(format string, words ...interface{}) {
+	l.printMessage(format, IconBulb, "trace", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Error log message
+func (l *MemoryLogger) Error(format string, words ...interface{}) {
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Exception log message
+func (l *MemoryLogger) Exception(err error, format string, words ...interface{}) {
+	if format == "" {
+		format = err.Error()
+	} else {
+		format = format + ", err " + err.Error()
+	}
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// LogError log message
+func (l *MemoryLogger) LogError(message error) {
+	if message != nil {
+		l.printMessage(message.Error(), IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv))
+	}
+}
+
+// Fatal log message
+func (l *MemoryLogger) Fatal(format string, words ...interface{}) {
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// FatalError log message
+func (l *MemoryLogger) FatalError(e error, format string, words ...interface{}) {
+	l.Error(format, words...)
+	if e != nil {
+		panic(e)
+	}
+}
+
+// printCorrelated prints a message using a correlation id already resolved by the caller
+func (l *MemoryLogger) printCorrelated(correlationId string, format string, icon LoggerIcon, level string, words ...interface{}) {
+	l.printMessage(format, icon, level, correlationId, words...)
+}
+
+// printMessage writes a message without fields
+func (l *MemoryLogger) printMessage(format string, icon LoggerIcon, level string, correlationId string, words ...interface{}) {
+	l.printStructured(correlationId, messageMeta{}, format, icon, level, words...)
+}
+
+// printStructured adds the message to the ring buffer, overwriting the oldest
+// one once the buffer is full
+func (l *MemoryLogger) printStructured(correlationId string, meta messageMeta, format string, icon LoggerIcon, level string, words ...interface{}) {
+	msg := newLogMessage(format, icon, level, l.useIcons, "", words...)
+	msg.SchemaVersion = l.schemaVersion
+	meta.apply(&msg)
+	if l.userCorrelationId && correlationId != "" {
+		msg.Message = "[" + correlationId + "] " + msg.Message
+	}
+	redactMessage(l.redactors, &msg)
+
+	l.bufferMutex.Lock()
+	defer l.bufferMutex.Unlock()
+
+	l.messages[l.next] = msg
+	l.next = (l.next + 1) % len(l.messages)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Dump returns the buffered messages from the oldest to the most recent
+func (l *MemoryLogger) Dump() []LogMessage {
+	l.bufferMutex.Lock()
+	defer l.bufferMutex.Unlock()
+
+	if !l.full {
+		return append([]LogMessage{}, l.messages[:l.next]...)
+	}
+
+	messages := make([]LogMessage, 0, len(l.messages))
+	messages = append(messages, l.messages[l.next:]...)
+	return append(messages, l.messages[:l.next]...)
+}
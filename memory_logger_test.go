@@ -0,0 +1,81 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func memoryMessages(logger *MemoryLogger) []string {
+	messages := make([]string, 0)
+	for _, msg := range logger.Dump() {
+		messages = append(messages, msg.Message)
+	}
+	return messages
+}
+
+func TestMemoryLogger_Dump(t *testing.T) {
+	tests := []struct {
+		name     string
+		logged   int
+		expected []string
+	}{
+		{name: "empty", logged: 0, expected: []string{}},
+		{name: "partially filled", logged: 2, expected: []string{"message 0", "message 1"}},
+		{name: "full", logged: 3, expected: []string{"message 0", "message 1", "message 2"}},
+		{name: "wrapped", logged: 5, expected: []string{"message 2", "message 3", "message 4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := (&MemoryLogger{capacity: 3}).Init().(*MemoryLogger)
+			for i := 0; i < tt.logged; i++ {
+				logger.Info("message %d", i)
+			}
+
+			assert.Equal(t, tt.expected, memoryMessages(logger))
+		})
+	}
+}
+
+func TestLoggerService_AddMemoryLogger(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Warning,
+		Loggers:  []Logger{mockLogger},
+	}
+	memory := service.AddMemoryLogger(10)
+
+	service.Debug("loading %s", "config")
+	service.WithField("user_id", 42).Info("user signed in")
+	service.Error("payment failed")
+
+	// Only the error reaches the other loggers, the memory keeps every level
+	assert.Len(t, mockLogger.PrintedMessages, 1)
+	dump := memory.Dump()
+	assert.Equal(t, []string{"loading config", "user signed in", "payment failed"}, memoryMessages(memory))
+	assert.Equal(t, "debug", dump[0].Level)
+	assert.Equal(t, map[string]any{"user_id": 42}, dump[1].Fields)
+	assert.Equal(t, "error", dump[2].Level)
+}
+
+func TestMemoryLogger_Concurrent(t *testing.T) {
+	logger := (&MemoryLogger{capacity: 50}).Init().(*MemoryLogger)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				logger.Info(fmt.Sprintf("worker %d message %d", worker, j))
+				logger.Dump()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, logger.Dump(), 50)
+}
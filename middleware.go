@@ -0,0 +1,89 @@
+package log
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// requestIdHeader is the header carrying the request id
+const requestIdHeader = "X-Request-Id"
+
+// statusRecorder is an http.ResponseWriter remembering the status code sent
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the first status code and sends it
+func (w *statusRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write sends the body, an implicit 200 status when no header was written
+func (w *statusRecorder) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends the buffered data when the wrapped writer supports it
+func (w *statusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// HTTPMiddleware logs every request once it is served with its method, path,
+// status code and duration, at error level for 5xx responses and at info level
+// otherwise. The X-Request-Id header, generated when missing, is stored in the
+// request context as the correlation id, so the *Ctx methods and ForRequest
+// used by the handlers pick it up, and echoed in the response.
+//
+// Example:
+//
+//	service := log.New().WithCorrelationId()
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {
+//	    service.InfoCtx(r.Context(), "Loading users")
+//	})
+//	http.ListenAndServe(":8080", service.HTTPMiddleware(mux))
+//	// Output: [req-123] Loading users
+//	// Output: [req-123] GET /api/users 200 in 1.2ms method=GET path=/api/users request_id=req-123 status=200 duration_ms=1
+func (l *LoggerService) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(requestIdHeader)
+		if requestId == "" {
+			requestId = uuid.New().String()
+			r.Header.Set(requestIdHeader, requestId)
+		}
+		w.Header().Set(requestIdHeader, requestId)
+		r = r.WithContext(ContextWithCorrelationId(r.Context(), requestId))
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := nowFunc()
+		next.ServeHTTP(recorder, r)
+		duration := nowFunc().Sub(start)
+
+		entry := l.ForRequest(r).WithFields(map[string]any{
+			"status":      recorder.status,
+			"duration_ms": duration.Milliseconds(),
+		})
+		if recorder.status >= http.StatusInternalServerError {
+			entry.Error("%s %s %d in %s", r.Method, r.URL.Path, recorder.status, duration)
+		} else {
+			entry.Info("%s %s %d in %s", r.Method, r.URL.Path, recorder.status, duration)
+		}
+	})
+}
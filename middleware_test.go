@@ -0,0 +1,81 @@
+package log
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerService_HTTPMiddleware(t *testing.T) {
+	originalNow := nowFunc
+	defer func() { nowFunc = originalNow }()
+	start := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	calls := 0
+	nowFunc = func() time.Time {
+		calls++
+		return start.Add(time.Duration(calls-1) * 15 * time.Millisecond)
+	}
+
+	tests := []struct {
+		name      string
+		requestId string
+		status    int
+		level     string
+	}{
+		{name: "info for a success", requestId: "req-1", status: http.StatusOK, level: "info"},
+		{name: "info for a client error", requestId: "req-2", status: http.StatusNotFound, level: "info"},
+		{name: "error for a server error", requestId: "req-3", status: http.StatusBadGateway, level: "error"},
+		{name: "generated request id", status: http.StatusCreated, level: "info"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			memory := (&MemoryLogger{}).Init().(*MemoryLogger)
+			service := &LoggerService{
+				LogLevel: Info,
+				Loggers:  []Logger{memory},
+			}
+
+			var handlerCorrelationId string
+			handler := service.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerCorrelationId = service.resolveCorrelationId(r.Context())
+				if tt.status != http.StatusOK {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte("body"))
+			}))
+
+			request := httptest.NewRequest("POST", "/api/users", nil)
+			if tt.requestId != "" {
+				request.Header.Set("X-Request-Id", tt.requestId)
+			}
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, request)
+
+			requestId := response.Header().Get("X-Request-Id")
+			assert.NotEmpty(t, requestId)
+			if tt.requestId != "" {
+				assert.Equal(t, tt.requestId, requestId)
+			}
+			assert.Equal(t, requestId, handlerCorrelationId)
+			assert.Equal(t, tt.status, response.Code)
+
+			messages := memory.Dump()
+			assert.Len(t, messages, 1)
+			assert.Equal(t, tt.level, messages[0].Level)
+			assert.Equal(t, fmt.Sprintf("POST /api/users %d in 15ms", tt.status), messages[0].Message)
+			assert.Equal(t, map[string]any{
+				"method":      "POST",
+				"path":        "/api/users",
+				"request_id":  requestId,
+				"status":      tt.status,
+				"duration_ms": int64(15),
+			}, messages[0].Fields)
+		})
+	}
+}
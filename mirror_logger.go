@@ -0,0 +1,142 @@
+package log
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// MirrorLogger Command Line Logger implementation that mirrors every line to a
+// file. Each message is composed once, with the CmdLogger formatting, and the
+// same line is written colored to the console and without colors to the file,
+// so the two can never drift apart when the formatting options change.
+type MirrorLogger struct {
+	CmdLogger
+	filename string
+	console  io.Writer
+	mirror   *mirrorWriter
+}
+
+// mirrorWriter writes every line to the console as it is and to the file with
+// the colors stripped, under a single lock so both see the lines in the same order
+type mirrorWriter struct {
+	console io.Writer
+	file    io.WriteCloser
+	mutex   sync.Mutex
+}
+
+func (l *MirrorLogger) Init() Logger {
+	console := l.console
+	noColors := false
+	if console == nil {
+		console = os.Stdout
+		noColors = !colorTerminal(console)
+	}
+
+	mirror := &mirrorWriter{console: console}
+	if l.filename != "" {
+		file, err := os.OpenFile(l.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
+		if err != nil {
+			panic(err)
+		}
+		mirror.file = file
+	}
+
+	return &MirrorLogger{
+		CmdLogger: CmdLogger{writer: mirror, noColors: noColors},
+		filename:  l.filename,
+		console:   console,
+		mirror:    mirror,
+	}
+}
+
+// AddMirrorLogger adds a command line logger mirroring every line to the file
+// to the LoggerService. The console gets the colored line and the file the
+// same line without colors. Use it instead of AddCmdLogger, or remove the
+// command line logger, to avoid printing every line twice.
+//
+// Example:
+//
+//	service := log.New()
+//	log.RemoveLogger[*log.CmdLogger]()
+//	service.AddMirrorLogger("app.log")
+//	service.Warn("Disk usage at %d%%", 90)
+//	// Console: \x1b[33mDisk usage at 90%\x1b[0m
+//	// Content of app.log: Disk usage at 90%
+func (l *LoggerService) AddMirrorLogger(filename string) {
+	l.register(&MirrorLogger{filename: filename})
+}
+
+// registrationKey lets loggers writing to different files be registered together
+func (l *MirrorLogger) registrationKey() string {
+	return l.filename
+}
+
+// SetWriter sets the console writer, the lines are still mirrored to the file.
+// Colors are disabled when the console is not a terminal unless they were set
+// with UseColors or ForceColors
+func (l *MirrorLogger) SetWriter(w io.Writer) {
+	l.mirror.mutex.Lock()
+	l.mirror.console = w
+	l.mirror.mutex.Unlock()
+
+	l.console = w
+	if !l.colorsSet {
+		l.noColors = !colorTerminal(w)
+	}
+}
+
+// ForceColors forces the colors on even when the console is not a terminal,
+// false restores the auto-detection
+func (l *MirrorLogger) ForceColors(value bool) {
+	l.colorsSet = value
+	if value {
+		enableVirtualTerminal(l.console)
+	}
+	l.noColors = !value && !colorTerminal(l.console)
+}
+
+// Close closes the file, later lines are only written to the console
+func (l *MirrorLogger) Close() error {
+	return l.mirror.Close()
+}
+
+// Write writes the line to the console and its color stripped copy to the file
+func (w *mirrorWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	n, err := w.console.Write(p)
+	if w.file != nil {
+		if _, fileErr := io.WriteString(w.file, stripColors(string(p))); err == nil {
+			err = fileErr
+		}
+	}
+	return n, err
+}
+
+// Close closes the file once
+func (w *mirrorWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// Flush syncs the file to disk, so flushing the CmdLogger commits the mirrored lines
+func (w *mirrorWriter) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	file, ok := w.file.(interface{ Sync() error })
+	if !ok {
+		return nil
+	}
+	return file.Sync()
+}
@@ -0,0 +1,74 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirrorLogger_FileIsStrippedConsole(t *testing.T) {
+	var console bytes.Buffer
+	filename := filepath.Join(t.TempDir(), "mirror.log")
+	logger := (&MirrorLogger{filename: filename, console: &console}).Init().(*MirrorLogger)
+	logger.UseIcons(true)
+	logger.UseCorrelationId(true)
+
+	logger.Warn("disk at %d%%", 90)
+	logger.printCorrelated("req-1", "multi\nline", IconInfo, "info")
+	logger.LogHighlight("deploying %s", Info, 93, "v1.2.0")
+	assert.NoError(t, logger.Close())
+
+	content, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+
+	assert.Contains(t, console.String(), "\x1b[33m")
+	assert.Equal(t, stripColors(console.String()), string(content))
+	assert.Equal(t, "⚠ disk at 90%\n[req-1] ℹ multi\nline\ndeploying v1.2.0\n", string(content))
+}
+
+func TestMirrorLogger_WriteAfterClose(t *testing.T) {
+	var console bytes.Buffer
+	filename := filepath.Join(t.TempDir(), "mirror.log")
+	logger := (&MirrorLogger{filename: filename, console: &console}).Init().(*MirrorLogger)
+
+	logger.Info("before close")
+	assert.NoError(t, logger.Close())
+	assert.NoError(t, logger.Close())
+	logger.Info("after close")
+
+	content, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, "before close\n", string(content))
+	assert.Contains(t, console.String(), "after close")
+}
+
+func TestLoggerService_AddMirrorLogger(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "mirror.log")
+	service := &LoggerService{LogLevel: Info}
+	service.AddMirrorLogger(filename)
+	service.Error("payment %s failed", "a1")
+	assert.NoError(t, service.Close())
+
+	content, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, "payment a1 failed\n", string(content))
+}
+
+func TestMirrorLogger_SetWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "mirror.log")
+	logger := (&MirrorLogger{filename: filename, console: io.Discard}).Init().(*MirrorLogger)
+	defer logger.Close()
+
+	var console bytes.Buffer
+	logger.SetWriter(&console)
+	logger.Warn("careful")
+
+	content, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, "careful\n", console.String())
+	assert.Equal(t, "careful\n", string(content))
+}
@@ -14,9 +14,11 @@ import (
 // MockedLogMessage represents a captured log message for testing purposes.
 // It contains the essential components of a log message without the timestamp.
 type MockedLogMessage struct {
-	Message string // The formatted log message
-	Level   string // The log level (info, error, warn, etc.)
-	Icon    string // The icon used in the message (if any)
+	Message string        // The formatted log message
+	Level   string        // The log level (info, error, warn, etc.)
+	Icon    string        // The icon used in the message (if any)
+	Format  string        // The format string before interpolation
+	Args    []interface{} // The arguments passed with the format string
 }
 
 // MockLogger implements the Logger interface for testing purposes.
@@ -48,6 +50,7 @@ type MockLogger struct {
 	userCorrelationId  bool               // Whether correlation IDs are enabled
 	useIcons           bool               // Whether icons are enabled
 	writer             io.Writer          // The output writer (usually stdout for testing)
+	redactors          []Redactor         // Transformers run on every recorded message
 }
 
 // Init initializes a new MockLogger with default settings.
@@ -135,6 +138,11 @@ func (l *MockLogger) UseIcons(value bool) {
 	l.useIcons = value
 }
 
+// SetRedactors sets the redactors run on every message before it is recorded.
+func (l *MockLogger) SetRedactors(redactors []Redactor) {
+	l.redactors = redactors
+}
+
 // Log records a message with the specified level.
 //
 // Example:
@@ -415,7 +423,7 @@ func (l *MockLogger) LogError(message error) {
 //	    t.Error("Wrong log level")
 //	}
 func (l *MockLogger) TaskError(format string, isComplete bool, words ...interface{}) {
-	l.printMessage(format, "", "error", true, isComplete, l.useTimestamp)
+	l.printMessage(format, "", "error", true, isComplete, words...)
 }
 
 // Fatal records a fatal error message.
@@ -466,6 +474,12 @@ func (l *MockLogger) FatalError(e error, format string, words ...interface{}) {
 //
 //	l.printMessage("Processing %s", IconInfo, "info", false, false, "data")
 func (l *MockLogger) printMessage(format string, icon LoggerIcon, level string, isTask bool, isComplete bool, words ...interface{}) {
-	l.LastPrintedMessage = MockedLogMessage{Message: fmt.Sprintf(format, words...), Level: level, Icon: string(icon)}
+	l.LastPrintedMessage = MockedLogMessage{
+		Message: redact(l.redactors, formatMessage(format, words...)),
+		Level:   level,
+		Icon:    string(icon),
+		Format:  format,
+		Args:    append([]interface{}{}, words...),
+	}
 	l.PrintedMessages = append(l.PrintedMessages, l.LastPrintedMessage)
 }
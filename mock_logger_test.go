@@ -85,9 +85,12 @@ func TestMockLogger_Log(t *testing.T) {
 			assert.Equal(t, tt.expected.Message, mockLogger.LastPrintedMessage.Message)
 			assert.Equal(t, tt.expected.Icon, mockLogger.LastPrintedMessage.Icon)
 
-			// Verify message was added to history
+			// Verify message was added to history with its format and args
+			expected := tt.expected
+			expected.Format = tt.format
+			expected.Args = tt.args
 			assert.Len(t, mockLogger.PrintedMessages, 1)
-			assert.Equal(t, tt.expected, mockLogger.PrintedMessages[0])
+			assert.Equal(t, expected, mockLogger.PrintedMessages[0])
 		})
 	}
 
@@ -199,9 +202,12 @@ func TestMockLogger_LogIcon(t *testing.T) {
 			assert.Equal(t, tt.expected.Message, mockLogger.LastPrintedMessage.Message)
 			assert.Equal(t, tt.expected.Icon, mockLogger.LastPrintedMessage.Icon)
 
-			// Verify message was added to history
+			// Verify message was added to history with its format and args
+			expected := tt.expected
+			expected.Format = tt.format
+			expected.Args = tt.args
 			assert.Len(t, mockLogger.PrintedMessages, 1)
-			assert.Equal(t, tt.expected, mockLogger.PrintedMessages[0])
+			assert.Equal(t, expected, mockLogger.PrintedMessages[0])
 		})
 	}
 
@@ -240,3 +246,29 @@ func TestMockLogger_LogIcon(t *testing.T) {
 		assert.Equal(t, "test message", mockLogger.LastPrintedMessage.Message)
 	})
 }
+
+func TestMockLogger_TaskError(t *testing.T) {
+	mockLogger := &MockLogger{}
+	mockLogger = mockLogger.Init().(*MockLogger)
+
+	mockLogger.TaskError("task %s failed", true, "backup")
+
+	assert.Equal(t, "task backup failed", mockLogger.LastPrintedMessage.Message)
+	assert.Equal(t, "error", mockLogger.LastPrintedMessage.Level)
+}
+
+func TestMockLogger_FormatAndArgs(t *testing.T) {
+	mockLogger := (&MockLogger{}).Init().(*MockLogger)
+
+	args := []interface{}{"bob", 3}
+	mockLogger.Info("user %s failed %d logins", args...)
+	args[0] = "changed"
+	mockLogger.Warn("preformatted user bob")
+
+	assert.Equal(t, "user bob failed 3 logins", mockLogger.PrintedMessages[0].Message)
+	assert.Equal(t, "user %s failed %d logins", mockLogger.PrintedMessages[0].Format)
+	assert.Equal(t, []interface{}{"bob", 3}, mockLogger.PrintedMessages[0].Args)
+
+	assert.Equal(t, "preformatted user bob", mockLogger.LastPrintedMessage.Format)
+	assert.Empty(t, mockLogger.LastPrintedMessage.Args)
+}
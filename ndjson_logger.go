@@ -0,0 +1,258 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+)
+
+// NDJSONLogger newline delimited JSON Logger implementation, every message is
+// encoded as a LogMessage and written as a single line with a single Write, so
+// a reader never sees a partial or interleaved record
+type NDJSONLogger struct {
+	useTimestamp      bool
+	userCorrelationId bool
+	useIcons          bool
+	correlationEnv    string
+	schemaVersion     string
+	writer            io.Writer
+	redactors         []Redactor
+	writerMutex       sync.Mutex
+}
+
+func (l *NDJSONLogger) Init() Logger {
+	writer := l.writer
+	if writer == nil {
+		writer = os.Stdout
+	}
+
+	return &NDJSONLogger{
+		useTimestamp:      false,
+		userCorrelationId: false,
+		useIcons:          false,
+		writer:            writer,
+	}
+}
+
+// AddNDJSONLogger adds a newline delimited JSON logger to the LoggerService.
+// Every message is written to the writer as one LogMessage JSON object per line,
+// a nil writer uses stdout.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddNDJSONLogger(os.Stdout)
+//	service.Info("Hello from ndjson logger!")
+//	// Output: {"level":"info","message":"Hello from ndjson logger!",...}
+func (l *LoggerService) AddNDJSONLogger(writer io.Writer) {
+	l.register(&NDJSONLogger{
+		userCorrelationId: l.useCorrelationId,
+		useIcons:          l.useIcons,
+		useTimestamp:      l.UseTimestamp,
+		writer:            writer,
+	})
+}
+
+func (l *NDJSONLogger) IsTimestampEnabled() bool {
+	return l.useTimestamp
+}
+
+func (l *NDJSONLogger) UseTimestamp(value bool) {
+	l.useTimestamp = value
+}
+
+func (l *NDJSONLogger) UseCorrelationId(value bool) {
+	l.userCorrelationId = value
+}
+
+func (l *NDJSONLogger) UseIcons(value bool) {
+	l.useIcons = value
+}
+
+// SetCorrelationEnv sets the environment variable the correlation id is read
+// from, CORRELATION_ID when empty
+func (l *NDJSONLogger) SetCorrelationEnv(name string) {
+	l.correlationEnv = name
+}
+
+// SetRedactors sets the redactors run on every message before it is written
+func (l *NDJSONLogger) SetRedactors(redactors []Redactor) {
+	l.redactors = redactors
+}
+
+// SetSchemaVersion stamps the version into every written LogMessage, an empty
+// version leaves the field out
+func (l *NDJSONLogger) SetSchemaVersion(version string) {
+	l.schemaVersion = version
+}
+
+// Log Log information message
+func (l *NDJSONLogger) Log(format string, level Level, words ...interface{}) {
+	switch level {
+	case 0:
+		l.printMessage(format, "", "error", correlationIdFromEnv(l.correlationEnv), words...)
+	case 1:
+		l.printMessage(format, "", "warn", correlationIdFromEnv(l.correlationEnv), words...)
+	case 2:
+		l.printMessage(format, "", "info", correlationIdFromEnv(l.correlationEnv), words...)
+	case 3:
+		l.printMessage(format, "", "debug", correlationIdFromEnv(l.correlationEnv), words...)
+	case 4:
+		l.printMessage(format, "", "trace", correlationIdFromEnv(l.correlationEnv), words...)
+	}
+}
+
+// LogIcon Log information message
+func (l *NDJSONLogger) LogIcon(icon LoggerIcon, format string, level Level, words ...interface{}) {
+	switch level {
+	case 0:
+		l.printMessage(format, icon, "error", correlationIdFromEnv(l.correlationEnv), words...)
+	case 1:
+		l.printMessage(format, icon, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+	case 2:
+		l.printMessage(format, icon, "info", correlationIdFromEnv(l.correlationEnv), words...)
+	case 3:
+		l.printMessage(format, icon, "debug", correlationIdFromEnv(l.correlationEnv), words...)
+	case 4:
+		l.printMessage(format, icon, "trace", correlationIdFromEnv(l.correlationEnv), words...)
+	}
+}
+
+// LogHighlight Log information message, the highlight color is dropped as
+// the message is written as JSON
+func (l *NDJSONLogger) LogHighlight(format string, level Level, highlightColor strcolor.ColorCode, words ...interface{}) {
+	l.Log(format, level, words...)
+}
+
+// Info log information message
+func (l *NDJSONLogger) Info(format string, words ...interface{}) {
+	l.printMessage(format, IconInfo, "info", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Success log message
+func (l *NDJSONLogger) Success(format string, words ...interface{}) {
+	l.printMessage(format, IconThumbsUp, "success", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Warn log message
+func (l *NDJSONLogger) Warn(format string, words ...interface{}) {
+	l.printMessage(format, IconWarning, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Command log message
+func (l *NDJSONLogger) Command(format string, words ...interface{}) {
+	l.printMessage(format, IconWrench, "command", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Disabled log message
+func (l *NDJSONLogger) Disabled(format string, words ...interface{}) {
+	l.printMessage(format, IconBlackSquare, "disabled", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Notice log message
+func (l *NDJSONLogger) Notice(format string, words ...interface{}) {
+	l.printMessage(format, IconFlag, "notice", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Debug log message
+func (l *NDJSONLogger) Debug(format string, words ...interface{}) {
+	l.printMessage(format, IconFire, "debug", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Trace log message
+func (l *NDJSONLogger) Trace(format string, words ...interface{}) {
+	l.printMessage(format, IconBulb, "trace", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Error log message
+func (l *NDJSONLogger) Error(format string, words ...interface{}) {
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Exception log message
+func (l *NDJSONLogger) Exception(err error, format string, words ...interface{}) {
+	if format == "" {
+		format = err.Error()
+	} else {
+		format = format + ", err " + err.Error()
+	}
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// LogError log message
+func (l *NDJSONLogger) LogError(message error) {
+	if message != nil {
+		l.printMessage(message.Error(), IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv))
+	}
+}
+
+// Fatal log message
+func (l *NDJSONLogger) Fatal(format string, words ...interface{}) {
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// FatalError log message
+func (l *NDJSONLogger) FatalError(e error, format string, words ...interface{}) {
+	l.Error(format, words...)
+	l.Flush()
+	if e != nil {
+		panic(e)
+	}
+}
+
+// SetWriter sets the writer the messages are written to
+func (l *NDJSONLogger) SetWriter(w io.Writer) {
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	l.writer = w
+}
+
+// Flush flushes the writer when it buffers its output
+func (l *NDJSONLogger) Flush() error {
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	return flushWriter(l.writer)
+}
+
+// printCorrelated prints a message using a correlation id already resolved by the caller
+func (l *NDJSONLogger) printCorrelated(correlationId string, format string, icon LoggerIcon, level string, words ...interface{}) {
+	l.printMessage(format, icon, level, correlationId, words...)
+}
+
+// printMessage writes a message without fields
+func (l *NDJSONLogger) printMessage(format string, icon LoggerIcon, level string, correlationId string, words ...interface{}) {
+	l.printStructured(correlationId, messageMeta{}, format, icon, level, words...)
+}
+
+// printStructured encodes the complete record first and then writes it with a
+// single Write under the writer mutex
+func (l *NDJSONLogger) printStructured(correlationId string, meta messageMeta, format string, icon LoggerIcon, level string, words ...interface{}) {
+	msg := newLogMessage(format, icon, level, l.useIcons, "", words...)
+	msg.SchemaVersion = l.schemaVersion
+	meta.apply(&msg)
+	if l.userCorrelationId && correlationId != "" {
+		msg.Message = "[" + correlationId + "] " + msg.Message
+	}
+	redactMessage(l.redactors, &msg)
+
+	record, err := json.Marshal(msg)
+	if err != nil {
+		record, _ = json.Marshal(LogMessage{
+			Level:     level,
+			Message:   fmt.Sprintf("failed to encode log message: %v", err),
+			Timestamp: msg.Timestamp,
+		})
+	}
+	record = append(record, '\n')
+
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	l.writer.Write(record)
+}
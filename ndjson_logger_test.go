@@ -0,0 +1,79 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chunkRecorder keeps every Write call apart to check records are never split
+type chunkRecorder struct {
+	mutex  sync.Mutex
+	chunks [][]byte
+}
+
+func (r *chunkRecorder) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.chunks = append(r.chunks, append([]byte{}, p...))
+	return len(p), nil
+}
+
+func TestNDJSONLogger_Record(t *testing.T) {
+	var output bytes.Buffer
+	logger := (&NDJSONLogger{writer: &output}).Init().(*NDJSONLogger)
+	logger.UseCorrelationId(true)
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{logger},
+	}
+
+	service.WithField("user_id", 42).Info("user logged in")
+	service.WarnCtx(ContextWithCorrelationId(context.Background(), "req-1"), "disk at %d%%", 90)
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+
+	var first LogMessage
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "info", first.Level)
+	assert.Equal(t, "user logged in", first.Message)
+	assert.Equal(t, float64(42), first.Fields["user_id"])
+
+	var second LogMessage
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "warn", second.Level)
+	assert.Equal(t, "[req-1] disk at 90%", second.Message)
+}
+
+func TestNDJSONLogger_ConcurrentWriters(t *testing.T) {
+	recorder := &chunkRecorder{}
+	logger := (&NDJSONLogger{writer: recorder}).Init().(*NDJSONLogger)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				logger.Info("writer %d message %d with a fairly long payload to encode", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, recorder.chunks, 500)
+	for _, chunk := range recorder.chunks {
+		assert.True(t, bytes.HasSuffix(chunk, []byte("\n")))
+		assert.Equal(t, 1, bytes.Count(chunk, []byte("\n")))
+
+		var msg LogMessage
+		assert.NoError(t, json.Unmarshal(chunk, &msg), "invalid record %q", chunk)
+	}
+}
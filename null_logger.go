@@ -0,0 +1,147 @@
+package log
+
+import (
+	"context"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+)
+
+// NullLogger Logger implementation that discards every message, used to turn
+// logging off entirely, see LoggerService.Silence
+type NullLogger struct{}
+
+func (l *NullLogger) Init() Logger {
+	return &NullLogger{}
+}
+
+func (l *NullLogger) IsTimestampEnabled() bool {
+	return false
+}
+
+func (l *NullLogger) UseTimestamp(value bool) {}
+
+func (l *NullLogger) UseCorrelationId(value bool) {}
+
+func (l *NullLogger) UseIcons(value bool) {}
+
+// Log discards the message
+func (l *NullLogger) Log(format string, level Level, words ...interface{}) {}
+
+// LogIcon discards the message
+func (l *NullLogger) LogIcon(icon LoggerIcon, format string, level Level, words ...interface{}) {}
+
+// LogHighlight discards the message
+func (l *NullLogger) LogHighlight(format string, level Level, highlightColor strcolor.ColorCode, words ...interface{}) {
+}
+
+// Info discards the message
+func (l *NullLogger) Info(format string, words ...interface{}) {}
+
+// Success discards the message
+func (l *NullLogger) Success(format string, words ...interface{}) {}
+
+// Warn discards the message
+func (l *NullLogger) Warn(format string, words ...interface{}) {}
+
+// Command discards the message
+func (l *NullLogger) Command(format string, words ...interface{}) {}
+
+// Disabled discards the message
+func (l *NullLogger) Disabled(format string, words ...interface{}) {}
+
+// Notice discards the message
+func (l *NullLogger) Notice(format string, words ...interface{}) {}
+
+// Debug discards the message
+func (l *NullLogger) Debug(format string, words ...interface{}) {}
+
+// Trace discards the message
+func (l *NullLogger) Trace(format string, words ...interface{}) {}
+
+// Error discards the message
+func (l *NullLogger) Error(format string, words ...interface{}) {}
+
+// Exception discards the message
+func (l *NullLogger) Exception(err error, format string, words ...interface{}) {}
+
+// LogError discards the message
+func (l *NullLogger) LogError(message error) {}
+
+// Fatal discards the message
+func (l *NullLogger) Fatal(format string, words ...interface{}) {}
+
+// FatalError discards the message and still panics with the error so the
+// caller stops as it would with any other logger
+func (l *NullLogger) FatalError(e error, format string, words ...interface{}) {
+	if e != nil {
+		panic(e)
+	}
+}
+
+// Silence replaces the loggers with a single NullLogger so nothing is logged
+// and messages are dropped before any formatting, filtering or counting, the
+// current loggers are kept for Unsilence. Calling Silence again does nothing.
+//
+// Example:
+//
+//	service := log.New()
+//	service.Silence()
+//	service.Info("This won't be logged")
+//	service.Unsilence()
+//	service.Info("This will be logged")
+func (l *LoggerService) Silence() *LoggerService {
+	l.loggersMutex.Lock()
+	defer l.loggersMutex.Unlock()
+
+	if l.silencedLoggers != nil {
+		return l
+	}
+
+	l.silencedLoggers = append([]Logger{}, l.Loggers...)
+	l.Loggers = []Logger{&NullLogger{}}
+	return l
+}
+
+// Unsilence restores the loggers replaced by Silence, loggers added while the
+// service was silenced are kept after the restored ones.
+//
+// Example:
+//
+//	service := log.New()
+//	service.Silence()
+//	service.Unsilence()
+//	service.Info("Back to normal")
+func (l *LoggerService) Unsilence() *LoggerService {
+	l.loggersMutex.Lock()
+	defer l.loggersMutex.Unlock()
+
+	if l.silencedLoggers == nil {
+		return l
+	}
+
+	loggers := l.silencedLoggers
+	for _, logger := range l.Loggers {
+		if _, ok := logger.(*NullLogger); !ok {
+			loggers = append(loggers, logger)
+		}
+	}
+	l.Loggers = loggers
+	l.silencedLoggers = nil
+	return l
+}
+
+// isSilenced reports whether every logger the message goes to is a NullLogger,
+// so it can be dropped before it is formatted
+func (l *LoggerService) isSilenced(ctx context.Context) bool {
+	loggers := l.loggersFor(ctx)
+	if len(loggers) == 0 {
+		return false
+	}
+
+	for _, logger := range loggers {
+		if _, ok := logger.(*NullLogger); !ok {
+			return false
+		}
+	}
+	return true
+}
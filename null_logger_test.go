@@ -0,0 +1,54 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNullLogger_FatalError(t *testing.T) {
+	logger := (&NullLogger{}).Init()
+
+	assert.NotPanics(t, func() { logger.FatalError(nil, "fatal") })
+	assert.PanicsWithError(t, "boom", func() { logger.FatalError(errors.New("boom"), "fatal") })
+}
+
+func TestLoggerService_Silence(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+
+	service.Silence().Silence()
+	service.Info("hidden")
+	service.Error("hidden")
+	assert.Empty(t, mockLogger.PrintedMessages)
+	assert.Zero(t, service.Stats()["info"])
+	assert.True(t, service.isSilenced(context.Background()))
+
+	added := &MockLogger{}
+	service.AddLogger(added)
+	service.Info("only added")
+	assert.False(t, service.isSilenced(context.Background()))
+	assert.Len(t, added.PrintedMessages, 1)
+
+	service.Unsilence().Unsilence()
+	service.Info("visible")
+	assert.Equal(t, []Logger{mockLogger, added}, service.Loggers)
+	assert.Len(t, mockLogger.PrintedMessages, 1)
+	assert.Equal(t, "visible", mockLogger.LastPrintedMessage.Message)
+}
+
+func TestLoggerService_SilenceClose(t *testing.T) {
+	service := &LoggerService{LogLevel: Info}
+	channel := (&ChannelLogger{}).Init()
+	service.AddLogger(channel)
+
+	service.Silence()
+	assert.NoError(t, service.Close())
+	assert.Empty(t, service.Loggers)
+	assert.Nil(t, service.silencedLoggers)
+}
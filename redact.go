@@ -0,0 +1,71 @@
+package log
+
+import "regexp"
+
+// Redactor transforms the fully formatted message before it is written, such
+// as masking tokens or emails
+type Redactor func(message string) string
+
+// redactorLogger is implemented by loggers that run the service redactors on
+// every message before writing it
+type redactorLogger interface {
+	SetRedactors(redactors []Redactor)
+}
+
+// redact runs the redactors on the message in registration order
+func redact(redactors []Redactor, message string) string {
+	for _, redactor := range redactors {
+		message = redactor(message)
+	}
+	return message
+}
+
+// redactMessage runs the redactors on the text of a structured message
+func redactMessage(redactors []Redactor, msg *LogMessage) {
+	if len(redactors) == 0 {
+		return
+	}
+
+	msg.Message = redact(redactors, msg.Message)
+	if msg.Error != "" {
+		msg.Error = redact(redactors, msg.Error)
+	}
+}
+
+// RegexRedactor returns a Redactor replacing every match of the pattern with
+// the replacement, which can reference groups as in regexp.ReplaceAllString.
+// It panics if the pattern does not compile.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddRedactor(log.RegexRedactor(`[\w.]+@[\w.]+`, "[email]"))
+//	service.Info("Invite sent to %s", "jane@example.com")
+//	// Output: Invite sent to [email]
+func RegexRedactor(pattern, replacement string) Redactor {
+	expression := regexp.MustCompile(pattern)
+	return func(message string) string {
+		return expression.ReplaceAllString(message, replacement)
+	}
+}
+
+// AddRedactor registers a transformer applied by every logger to the fully
+// formatted message, after the words are substituted and before it is written
+// or emitted. Redactors run in registration order.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddRedactor(log.RegexRedactor(`token=\S+`, "token=***"))
+//	service.Info("Calling api with token=%s", "s3cr3t")
+//	// Output: Calling api with token=***
+func (l *LoggerService) AddRedactor(fn Redactor) *LoggerService {
+	l.redactors = append(l.redactors, fn)
+	for _, logger := range l.getLoggers() {
+		if rl, ok := logger.(redactorLogger); ok {
+			rl.SetRedactors(l.redactors)
+		}
+	}
+	return l
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexRedactor(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		replacement string
+		message     string
+		expected    string
+	}{
+		{"email", `[\w.]+@[\w.]+`, "[email]", "invite sent to jane@example.com", "invite sent to [email]"},
+		{"token group", `(token=)\S+`, "${1}***", "calling api with token=s3cr3t now", "calling api with token=*** now"},
+		{"no match", `secret`, "***", "nothing to hide", "nothing to hide"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RegexRedactor(tt.pattern, tt.replacement)(tt.message))
+		})
+	}
+}
+
+func TestLoggerService_AddRedactor(t *testing.T) {
+	var output bytes.Buffer
+	cmdLogger := &CmdLogger{writer: &output}
+	fileName := filepath.Join(t.TempDir(), "redact.log")
+	fileLogger := FileLogger{filename: fileName}.Init().(*FileLogger)
+	channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	_, ch := channelLogger.Subscribe("redact", func(LogMessage) bool { return true })
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{cmdLogger, fileLogger, channelLogger},
+	}
+
+	service.AddRedactor(RegexRedactor(`token=\S+`, "token=***"))
+	// Redactors run in registration order, so this one sees the masked token
+	service.AddRedactor(func(message string) string {
+		return strings.ReplaceAll(message, "***", "[redacted]")
+	})
+
+	// A logger added later gets the redactors too
+	mockLogger := &MockLogger{}
+	service.AddLogger(mockLogger)
+
+	service.Info("calling %s with token=%s", "api", "s3cr3t")
+	fileLogger.Close()
+
+	expected := "calling api with token=[redacted]"
+	assert.Equal(t, "\x1b[0m"+expected+"\x1b[0m\n", output.String())
+	content, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, expected+"\n", string(content))
+	assert.Equal(t, expected, (<-ch).Message)
+	assert.Equal(t, expected, mockLogger.LastPrintedMessage.Message)
+}
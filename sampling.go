@@ -0,0 +1,169 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// sampleIdleTimeout is how long a WithSampling window is kept without a message
+// of its format before it is dropped
+const sampleIdleTimeout = time.Minute
+
+// sampleWindow tracks the messages of a format string inside the current window
+type sampleWindow struct {
+	start      time.Time
+	last       time.Time
+	count      int
+	suppressed int
+	pending    func(suppressed int)
+}
+
+// sampler drops messages beyond a threshold, keyed by the format string so
+// distinct messages are never penalized together
+type sampler struct {
+	everyN    int
+	perSecond int
+	mutex     sync.Mutex
+	windows   map[string]*sampleWindow
+	lastSweep time.Time
+}
+
+// allow reports whether the message with the format should be logged and how
+// many messages of the format were suppressed since the last one logged,
+// pending is kept to log the suppressed count if no other message of the
+// format arrives before the window expires or the sampler is flushed
+func (s *sampler) allow(format string, pending func(suppressed int)) (bool, int) {
+	s.mutex.Lock()
+	now := nowFunc()
+	expired := s.sweep(now)
+	allowed, suppressed := s.count(format, now, pending)
+	s.mutex.Unlock()
+
+	for _, window := range expired {
+		window.pending(window.suppressed)
+	}
+	return allowed, suppressed
+}
+
+// count must be called with the mutex held
+func (s *sampler) count(format string, now time.Time, pending func(suppressed int)) (bool, int) {
+	window, ok := s.windows[format]
+	if !ok {
+		s.windows[format] = &sampleWindow{start: now, last: now, count: 1}
+		return true, 0
+	}
+
+	window.last = now
+	if s.perSecond > 0 && now.Sub(window.start) >= time.Second {
+		suppressed := window.suppressed
+		*window = sampleWindow{start: now, last: now, count: 1}
+		return true, suppressed
+	}
+
+	window.count++
+	if s.everyN > 0 && (window.count-1)%s.everyN == 0 {
+		suppressed := window.suppressed
+		window.suppressed = 0
+		return true, suppressed
+	}
+	if s.perSecond > 0 && window.count <= s.perSecond {
+		return true, 0
+	}
+
+	window.suppressed++
+	window.pending = pending
+	return false, 0
+}
+
+// sweep drops the expired windows, at most once a second, and returns the ones
+// with suppressed messages still to report. It must be called with the mutex held
+func (s *sampler) sweep(now time.Time) []*sampleWindow {
+	if now.Sub(s.lastSweep) < time.Second {
+		return nil
+	}
+	s.lastSweep = now
+
+	expired := make([]*sampleWindow, 0)
+	for format, window := range s.windows {
+		if s.perSecond > 0 && now.Sub(window.start) < time.Second {
+			continue
+		}
+		if s.perSecond <= 0 && now.Sub(window.last) < sampleIdleTimeout {
+			continue
+		}
+		delete(s.windows, format)
+		if window.suppressed > 0 {
+			expired = append(expired, window)
+		}
+	}
+	return expired
+}
+
+// flush logs the suppressed count of every window and drops them all
+func (s *sampler) flush() {
+	s.mutex.Lock()
+	windows := s.windows
+	s.windows = make(map[string]*sampleWindow)
+	s.mutex.Unlock()
+
+	for _, window := range windows {
+		if window.suppressed > 0 {
+			window.pending(window.suppressed)
+		}
+	}
+}
+
+// WithSampling logs only the first of every everyN messages sharing the same
+// format string, the sampled message is preceded by a line with the number of
+// suppressed messages. Counts still pending are logged on Flush and Close.
+// A value of one or less disables sampling.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithSampling(100)
+//	for i := 0; i < 101; i++ {
+//	    service.Warn("cache miss for key %d", i)
+//	}
+//	// Output: cache miss for key 0
+//	// Output: ... 99 similar messages suppressed
+//	// Output: cache miss for key 100
+func (l *LoggerService) WithSampling(everyN int) *LoggerService {
+	if l.sampler != nil {
+		l.sampler.flush()
+	}
+	if everyN <= 1 {
+		l.sampler = nil
+		return l
+	}
+
+	l.sampler = &sampler{everyN: everyN, windows: make(map[string]*sampleWindow)}
+	return l
+}
+
+// WithRateLimit logs at most perSecond messages sharing the same format string
+// every second, the first message of the next second is preceded by a line with
+// the number of suppressed messages, counts still pending are logged once the
+// second is over, on Flush and on Close. A value of zero or less disables it.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithRateLimit(10)
+//	for i := 0; i < 1000; i++ {
+//	    service.Warn("queue is full")
+//	}
+//	// 10 lines are logged, then on the next second
+//	// Output: ... 990 similar messages suppressed
+func (l *LoggerService) WithRateLimit(perSecond int) *LoggerService {
+	if l.sampler != nil {
+		l.sampler.flush()
+	}
+	if perSecond <= 0 {
+		l.sampler = nil
+		return l
+	}
+
+	l.sampler = &sampler{perSecond: perSecond, windows: make(map[string]*sampleWindow)}
+	return l
+}
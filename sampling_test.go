@@ -0,0 +1,176 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockMessages(mockLogger *MockLogger) []string {
+	messages := make([]string, 0, len(mockLogger.PrintedMessages))
+	for _, msg := range mockLogger.PrintedMessages {
+		messages = append(messages, msg.Message)
+	}
+	return messages
+}
+
+func TestLoggerService_WithSampling(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+	service.WithSampling(3)
+
+	for i := 0; i < 7; i++ {
+		service.Warn("cache miss %d", i)
+	}
+	service.Warn("distinct message")
+
+	assert.Equal(t, []string{
+		"cache miss 0",
+		"... 2 similar messages suppressed",
+		"cache miss 3",
+		"... 2 similar messages suppressed",
+		"cache miss 6",
+		"distinct message",
+	}, mockMessages(mockLogger))
+	assert.Equal(t, "warn", mockLogger.PrintedMessages[1].Level)
+}
+
+func TestLoggerService_WithRateLimit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+	service.WithRateLimit(2)
+
+	for i := 0; i < 5; i++ {
+		service.Error("queue full %d", i)
+	}
+	service.Error("other failure")
+
+	now = now.Add(time.Second)
+	service.Error("queue full %d", 5)
+
+	assert.Equal(t, []string{
+		"queue full 0",
+		"queue full 1",
+		"other failure",
+		"... 3 similar messages suppressed",
+		"queue full 5",
+	}, mockMessages(mockLogger))
+
+	service.WithRateLimit(0)
+	for i := 0; i < 5; i++ {
+		service.Error("unlimited")
+	}
+	assert.Len(t, mockLogger.PrintedMessages, 10)
+}
+
+func TestLoggerService_SamplingPendingCounts(t *testing.T) {
+	t.Run("flush logs the pending count", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+		service.WithSampling(10)
+
+		for i := 0; i < 4; i++ {
+			service.Warn("cache miss %d", i)
+		}
+		assert.NoError(t, service.Flush())
+		service.Warn("cache miss %d", 4)
+
+		assert.Equal(t, []string{
+			"cache miss 0",
+			"... 3 similar messages suppressed",
+			"cache miss 4",
+		}, mockMessages(mockLogger))
+		assert.Equal(t, "warn", mockLogger.PrintedMessages[1].Level)
+	})
+
+	t.Run("close logs the pending count", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+		service.WithRateLimit(1)
+
+		for i := 0; i < 3; i++ {
+			service.Error("queue full")
+		}
+		assert.NoError(t, service.Close())
+
+		assert.Equal(t, []string{
+			"queue full",
+			"... 2 similar messages suppressed",
+		}, mockMessages(mockLogger))
+	})
+
+	t.Run("expired windows are dropped", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		nowFunc = func() time.Time { return now }
+		defer func() { nowFunc = time.Now }()
+
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+		service.WithRateLimit(1)
+
+		for i := 0; i < 3; i++ {
+			service.Error("queue full")
+		}
+		service.Error("disk full")
+
+		now = now.Add(time.Second)
+		service.Error("other failure")
+
+		assert.Equal(t, []string{
+			"queue full",
+			"disk full",
+			"... 2 similar messages suppressed",
+			"other failure",
+		}, mockMessages(mockLogger))
+		assert.Len(t, service.sampler.windows, 1)
+		assert.Contains(t, service.sampler.windows, "other failure")
+	})
+}
+
+func TestLoggerService_SampledFlag(t *testing.T) {
+	channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	_, ch := channelLogger.Subscribe("sampled", func(LogMessage) bool { return true })
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{channelLogger},
+	}
+
+	service.Warn("before sampling")
+	assert.False(t, (<-ch).Sampled)
+
+	service.WithSampling(2)
+	for i := 0; i < 3; i++ {
+		service.Warn("cache miss %d", i)
+	}
+
+	assert.Len(t, ch, 3)
+	msg := <-ch
+	assert.Equal(t, "cache miss 0", msg.Message)
+	assert.False(t, msg.Sampled, "nothing suppressed before the first message")
+	msg = <-ch
+	assert.Equal(t, "... 1 similar messages suppressed", msg.Message)
+	assert.True(t, msg.Sampled)
+	msg = <-ch
+	assert.Equal(t, "cache miss 2", msg.Message)
+	assert.True(t, msg.Sampled)
+}
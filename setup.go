@@ -0,0 +1,62 @@
+package log
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// Setup creates the global logger configured for a typical service, the level
+// is read from LOG_LEVEL and the output format from LOG_FORMAT, "json" or
+// "text". Without LOG_FORMAT the service logs colored text when stdout is a
+// terminal and newline delimited JSON otherwise. Timestamps and correlation
+// ids are enabled and the service name is stamped as the source of every
+// structured message. The service can still be tuned with the granular API.
+//
+// Example:
+//
+//	service := log.Setup("billing-api")
+//	defer service.Close()
+//	service.Info("Server started on port %d", 8080)
+//	// Output in a container: {"level":"info","message":"Server started on port 8080",...,"logger":"billing-api"}
+func Setup(serviceName string) *LoggerService {
+	globalLogger = setup(serviceName, os.Stdout)
+	return globalLogger
+}
+
+// setup builds the Setup service writing to out
+func setup(serviceName string, out io.Writer) *LoggerService {
+	service := newService()
+	service.WithTimestamp().WithCorrelationId().WithSource(serviceName)
+
+	useJSON := !isTerminal(out)
+	switch strings.ToLower(os.Getenv(LOG_FORMAT)) {
+	case "json":
+		useJSON = true
+	case "text":
+		useJSON = false
+	}
+
+	if useJSON {
+		service.AddNDJSONLogger(out)
+	} else {
+		service.AddLogger(&CmdLogger{writer: out})
+	}
+	return service
+}
+
+// isTerminal reports whether the writer is a terminal, anything that is not
+// a character device file, such as a pipe or a buffer, is not. Tests replace
+// it to take the terminal branch
+var isTerminal = func(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
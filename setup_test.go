@@ -0,0 +1,53 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("json when not a terminal", func(t *testing.T) {
+		t.Setenv(LOG_LEVEL, "warn")
+		var output bytes.Buffer
+		service := setup("billing-api", &output)
+
+		service.Info("hidden")
+		service.Warn("disk at %d%%", 90)
+
+		var msg LogMessage
+		assert.NoError(t, json.Unmarshal(output.Bytes(), &msg))
+		assert.Equal(t, "warn", msg.Level)
+		assert.Equal(t, "disk at 90%", msg.Message)
+		assert.Equal(t, "billing-api", msg.Source)
+		assert.False(t, msg.Timestamp.IsZero())
+		assert.True(t, service.UseTimestamp)
+		assert.True(t, service.useCorrelationId)
+	})
+
+	t.Run("colored text on a terminal", func(t *testing.T) {
+		originalIsTerminal := isTerminal
+		defer func() { isTerminal = originalIsTerminal }()
+		isTerminal = func(io.Writer) bool { return true }
+
+		var output bytes.Buffer
+		service := setup("billing-api", &output)
+		service.Warn("disk at %d%%", 90)
+
+		assert.True(t, strings.HasPrefix(output.String(), "\x1b[33m"))
+		assert.True(t, strings.HasSuffix(output.String(), "disk at 90%\x1b[0m\n"))
+	})
+
+	t.Run("format from the environment", func(t *testing.T) {
+		t.Setenv(LOG_FORMAT, "text")
+		var output bytes.Buffer
+		service := setup("billing-api", &output)
+		service.Info("started")
+
+		assert.True(t, strings.HasSuffix(output.String(), "started\x1b[0m\n"))
+	})
+}
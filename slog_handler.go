@@ -0,0 +1,163 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// SlogHandler is a slog.Handler that routes slog records through the loggers
+// of a LoggerService, so teams can adopt log/slog while keeping the colored
+// console and file outputs.
+type SlogHandler struct {
+	service *LoggerService
+	attrs   []slog.Attr
+	groups  []string
+}
+
+// NewSlogHandler creates a slog.Handler writing to the given service.
+// Record levels are mapped to the service levels: slog.LevelError and above to Error,
+// slog.LevelWarn to Warning, slog.LevelInfo to Info, slog.LevelDebug to Debug and
+// anything below slog.LevelDebug to Trace. Attributes are appended to the message
+// as key=value pairs with the values highlighted using the service HighlightColor.
+//
+// Example:
+//
+//	service := log.New()
+//	logger := slog.New(log.NewSlogHandler(service))
+//	logger.Info("request handled", "route", "/login", "status", 200)
+//	// Output: request handled route=/login status=200
+func NewSlogHandler(service *LoggerService) slog.Handler {
+	return &SlogHandler{service: service}
+}
+
+// slogLevel maps a slog level to the service level
+func slogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return Error
+	case level >= slog.LevelWarn:
+		return Warning
+	case level >= slog.LevelInfo:
+		return Info
+	case level >= slog.LevelDebug:
+		return Debug
+	default:
+		return Trace
+	}
+}
+
+// Enabled reports whether the service log level allows the record level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.service.level() >= slogLevel(level)
+}
+
+// Handle logs the record message followed by the handler and record attributes,
+// the attribute values are highlighted by the text loggers only so the
+// structured loggers keep them without color codes
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var builder strings.Builder
+	builder.WriteString(escapeVerbs(r.Message))
+
+	words := make([]interface{}, 0, len(h.attrs)+r.NumAttrs())
+	prefix := h.groupPrefix()
+	for _, attr := range h.attrs {
+		words = h.appendAttr(&builder, words, "", attr)
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		words = h.appendAttr(&builder, words, prefix, attr)
+		return true
+	})
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = context.WithValue(ctx, highlightContextKey, ColorCode(h.service.HighlightColor))
+	format := builder.String()
+	switch slogLevel(r.Level) {
+	case Error:
+		h.service.ErrorCtx(ctx, format, words...)
+	case Warning:
+		h.service.WarnCtx(ctx, format, words...)
+	case Info:
+		h.service.InfoCtx(ctx, format, words...)
+	case Debug:
+		h.service.DebugCtx(ctx, format, words...)
+	default:
+		h.service.TraceCtx(ctx, format, words...)
+	}
+
+	return nil
+}
+
+// WithAttrs returns a handler that appends the attributes to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	// attributes added here are qualified with the groups opened so far
+	prefix := h.groupPrefix()
+	result := h.clone()
+	for _, attr := range attrs {
+		if prefix != "" {
+			attr.Key = prefix + attr.Key
+		}
+		result.attrs = append(result.attrs, attr)
+	}
+
+	return result
+}
+
+// WithGroup returns a handler that qualifies the following attribute keys with the group name
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	result := h.clone()
+	result.groups = append(result.groups, name)
+	return result
+}
+
+// clone copies the handler so derived handlers never share their slices
+func (h *SlogHandler) clone() *SlogHandler {
+	return &SlogHandler{
+		service: h.service,
+		attrs:   append([]slog.Attr{}, h.attrs...),
+		groups:  append([]string{}, h.groups...),
+	}
+}
+
+// groupPrefix returns the key prefix for the groups opened on the handler
+func (h *SlogHandler) groupPrefix() string {
+	if len(h.groups) == 0 {
+		return ""
+	}
+
+	return strings.Join(h.groups, ".") + "."
+}
+
+// appendAttr writes the attribute as a key=%s pair and returns the words with
+// its value, group attributes are flattened using dotted keys
+func (h *SlogHandler) appendAttr(builder *strings.Builder, words []interface{}, prefix string, attr slog.Attr) []interface{} {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return words
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			words = h.appendAttr(builder, words, groupPrefix, groupAttr)
+		}
+		return words
+	}
+
+	builder.WriteString(" " + escapeVerbs(prefix+attr.Key) + "=%s")
+	return append(words, fmt.Sprintf("%v", attr.Value.Any()))
+}
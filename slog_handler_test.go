@@ -0,0 +1,114 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHandler_Levels(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    slog.Level
+		expected string
+	}{
+		{name: "error", level: slog.LevelError, expected: "error"},
+		{name: "warn", level: slog.LevelWarn, expected: "warn"},
+		{name: "info", level: slog.LevelInfo, expected: "info"},
+		{name: "debug", level: slog.LevelDebug, expected: "debug"},
+		{name: "below debug", level: slog.LevelDebug - 4, expected: "trace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := &MockLogger{}
+			service := &LoggerService{
+				LogLevel: Trace,
+				Loggers:  []Logger{mockLogger},
+			}
+
+			logger := slog.New(NewSlogHandler(service))
+			logger.Log(context.Background(), tt.level, "slog message")
+
+			assert.Equal(t, "slog message", mockLogger.LastPrintedMessage.Message)
+			assert.Equal(t, tt.expected, mockLogger.LastPrintedMessage.Level)
+		})
+	}
+}
+
+func TestSlogHandler_Enabled(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+	handler := NewSlogHandler(service)
+
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelError))
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelInfo))
+	assert.False(t, handler.Enabled(context.Background(), slog.LevelDebug))
+
+	slog.New(handler).Debug("hidden")
+	assert.Empty(t, mockLogger.PrintedMessages)
+}
+
+func TestSlogHandler_Attrs(t *testing.T) {
+	highlight := func(value string) string {
+		return GetColorString(ColorCode(strcolor.BrightYellow), value)
+	}
+
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel:       Info,
+		HighlightColor: strcolor.BrightYellow,
+		Loggers:        []Logger{mockLogger},
+	}
+	logger := slog.New(NewSlogHandler(service))
+
+	t.Run("record attributes", func(t *testing.T) {
+		logger.Info("request handled", "route", "/login", "status", 200)
+
+		assert.Equal(t, "request handled route="+highlight("/login")+" status="+highlight("200"), mockLogger.LastPrintedMessage.Message)
+	})
+
+	t.Run("handler attributes and groups accumulate", func(t *testing.T) {
+		child := logger.With("service", "auth").WithGroup("req").With("id", "abc")
+		child.Warn("slow", "ms", 120)
+
+		assert.Equal(t, "slow service="+highlight("auth")+" req.id="+highlight("abc")+" req.ms="+highlight("120"), mockLogger.LastPrintedMessage.Message)
+		assert.Equal(t, "warn", mockLogger.LastPrintedMessage.Level)
+	})
+
+	t.Run("derived handlers do not share attributes", func(t *testing.T) {
+		base := logger.With("a", 1)
+		base.With("b", 2)
+		base.Info("base")
+
+		assert.Equal(t, "base a="+highlight("1"), mockLogger.LastPrintedMessage.Message)
+	})
+
+	t.Run("group attributes are flattened", func(t *testing.T) {
+		logger.Info("grouped", slog.Group("user", slog.String("name", "bob")), slog.Attr{})
+
+		assert.Equal(t, "grouped user.name="+highlight("bob"), mockLogger.LastPrintedMessage.Message)
+	})
+}
+
+func TestSlogHandler_StructuredLoggersWithoutColors(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel:       Info,
+		HighlightColor: strcolor.BrightYellow,
+		Loggers:        []Logger{mockLogger},
+	}
+	memoryLogger := service.AddMemoryLogger(10)
+	logger := slog.New(NewSlogHandler(service))
+
+	logger.Info("disk 90% full", "mount", "/var")
+
+	assert.Equal(t, "disk 90% full mount=/var", memoryLogger.Dump()[0].Message)
+	assert.Equal(t, "disk 90% full mount="+GetColorString(ColorCode(strcolor.BrightYellow), "/var"), mockLogger.LastPrintedMessage.Message)
+}
@@ -0,0 +1,184 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// maxStackFrames is the most frames captured in a stack trace
+const maxStackFrames = 32
+
+// packagePrefix is the function path prefix of this package, used to skip the
+// logger's own frames when capturing a stack
+var packagePrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndex(name, "/")
+	return name[:slash+1+strings.Index(name[slash+1:], ".")+1]
+}()
+
+// captureStack returns the frames of the caller of the logger, skipping the
+// logger's own frames and the runtime ones, with the filtered frames removed
+func captureStack(prefixes []string) []runtime.Frame {
+	pcs := make([]uintptr, maxStackFrames+16)
+	count := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:count])
+
+	result := make([]runtime.Frame, 0, count)
+	for {
+		frame, more := frames.Next()
+		ownFrame := strings.HasPrefix(frame.Function, packagePrefix) && !strings.HasSuffix(frame.File, "_test.go")
+		if !ownFrame && !strings.HasPrefix(frame.Function, "runtime.") && len(result) < maxStackFrames {
+			result = append(result, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return filterFrames(result, prefixes)
+}
+
+// callerLocation returns the file:line of the first frame outside the logger,
+// skipping skip more frames for wrappers around the logger
+func callerLocation(skip int) string {
+	pcs := make([]uintptr, maxStackFrames+16)
+	count := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:count])
+
+	for {
+		frame, more := frames.Next()
+		ownFrame := strings.HasPrefix(frame.Function, packagePrefix) && !strings.HasSuffix(frame.File, "_test.go")
+		if !ownFrame {
+			if skip <= 0 {
+				return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+			}
+			skip--
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// callerPrefix returns the caller location to prepend to a format string, or
+// an empty string when the caller is disabled
+func (l *LoggerService) callerPrefix() string {
+	if !l.useCaller {
+		return ""
+	}
+
+	location := callerLocation(l.callerSkip)
+	if location == "" {
+		return ""
+	}
+	return escapeVerbs(location) + " "
+}
+
+// stackTrace returns the stack to append to an error message, or an empty
+// string when stack traces are disabled
+func (l *LoggerService) stackTrace() string {
+	if !l.stackTraces {
+		return ""
+	}
+
+	return formatFrames(captureStack(l.stackFilter))
+}
+
+// filterFrames drops the frames whose function path starts with any of the prefixes
+func filterFrames(frames []runtime.Frame, prefixes []string) []runtime.Frame {
+	if len(prefixes) == 0 {
+		return frames
+	}
+
+	filtered := make([]runtime.Frame, 0, len(frames))
+	for _, frame := range frames {
+		skip := false
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(frame.Function, prefix) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			filtered = append(filtered, frame)
+		}
+	}
+	return filtered
+}
+
+// formatFrames renders the frames one per line as the function followed by
+// its file:line, indented like a runtime stack trace
+func formatFrames(frames []runtime.Frame) string {
+	var builder strings.Builder
+	for _, frame := range frames {
+		builder.WriteString(fmt.Sprintf("\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line))
+	}
+	return builder.String()
+}
+
+// WithCaller prefixes every message with the file:line of the code that logged
+// it, e.g. main.go:42, after the timestamp and before the message.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithCaller(true)
+//	service.Info("Server started")
+//	// Output: main.go:42 Server started
+func (l *LoggerService) WithCaller(value bool) *LoggerService {
+	l.useCaller = value
+	return l
+}
+
+// SetCallerSkip skips n more frames when resolving the caller, for wrappers
+// that call the logger on behalf of their own callers.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	func logFailure(service *log.LoggerService, err error) {
+//	    service.Exception(err, "operation failed")
+//	}
+//
+//	service := log.New().WithCaller(true).SetCallerSkip(1)
+//	logFailure(service, err)
+//	// Output: main.go:10 operation failed, err ...
+func (l *LoggerService) SetCallerSkip(n int) *LoggerService {
+	l.callerSkip = n
+	return l
+}
+
+// WithStackTraces appends the stack of the caller to Error, Exception and Fatal
+// messages, skipping the logger's own frames. Capturing the stack is expensive
+// so it is disabled by default and never applied to other levels.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New().WithStackTraces(true)
+//	service.Error("Payment failed")
+//	// Output: Payment failed
+//	// main.charge
+//	//	/app/payments.go:42
+//	// main.main
+//	//	/app/main.go:10
+func (l *LoggerService) WithStackTraces(value bool) *LoggerService {
+	l.stackTraces = value
+	return l
+}
+
+// WithStackFilter drops the frames whose function path starts with any of the
+// prefixes from the captured stack traces, such as the frames of the logging
+// package or of common middleware, to keep the traces focused on the app.
+// Returns the LoggerService for method chaining.
+//
+// Example:
+//
+//	service := log.New()
+//	service.WithStackFilter([]string{"net/http.", "github.com/go-chi/"})
+func (l *LoggerService) WithStackFilter(prefixes []string) *LoggerService {
+	l.stackFilter = append([]string{}, prefixes...)
+	return l
+}
@@ -0,0 +1,189 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterFrames(t *testing.T) {
+	frames := []runtime.Frame{
+		{Function: "main.handler", File: "/app/main.go", Line: 42},
+		{Function: "github.com/cjlapao/common-go-logger.(*LoggerService).Error", File: "/log/logger_service.go", Line: 10},
+		{Function: "net/http.HandlerFunc.ServeHTTP", File: "/go/src/net/http/server.go", Line: 2136},
+		{Function: "github.com/acme/app/auth.Login", File: "/app/auth/login.go", Line: 7},
+	}
+
+	tests := []struct {
+		name     string
+		prefixes []string
+		expected []string
+	}{
+		{
+			name:     "no filter keeps every frame",
+			prefixes: nil,
+			expected: []string{"main.handler", "github.com/cjlapao/common-go-logger.(*LoggerService).Error", "net/http.HandlerFunc.ServeHTTP", "github.com/acme/app/auth.Login"},
+		},
+		{
+			name:     "drops frames matching any prefix",
+			prefixes: []string{"github.com/cjlapao/common-go-logger.", "net/http."},
+			expected: []string{"main.handler", "github.com/acme/app/auth.Login"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &LoggerService{}
+			service.WithStackFilter(tt.prefixes)
+
+			functions := make([]string, 0)
+			for _, frame := range filterFrames(frames, service.stackFilter) {
+				functions = append(functions, frame.Function)
+			}
+			assert.Equal(t, tt.expected, functions)
+		})
+	}
+}
+
+func TestFormatFrames(t *testing.T) {
+	frames := []runtime.Frame{
+		{Function: "main.handler", File: "/app/main.go", Line: 42},
+		{Function: "main.main", File: "/app/main.go", Line: 10},
+	}
+
+	assert.Equal(t, "\nmain.handler\n\t/app/main.go:42\nmain.main\n\t/app/main.go:10", formatFrames(frames))
+}
+
+// logPaymentFailure is a non-inlined caller so it shows up in the stack
+//
+//go:noinline
+func logPaymentFailure(service *LoggerService) {
+	service.Error("payment %s", "failed")
+}
+
+func TestLoggerService_WithStackTraces(t *testing.T) {
+	mockLogger := &MockLogger{}
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{mockLogger},
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		logPaymentFailure(service)
+		assert.Equal(t, "payment failed", mockLogger.LastPrintedMessage.Message)
+	})
+
+	service.WithStackTraces(true)
+
+	t.Run("error appends the caller stack", func(t *testing.T) {
+		logPaymentFailure(service)
+
+		message := mockLogger.LastPrintedMessage.Message
+		assert.True(t, strings.HasPrefix(message, "payment failed\n"+packagePrefix+"logPaymentFailure\n"), message)
+		assert.Contains(t, message, "stack_test.go:")
+		assert.Contains(t, message, "TestLoggerService_WithStackTraces")
+		assert.NotContains(t, message, "(*LoggerService).Error")
+		assert.NotContains(t, message, "runtime.goexit")
+	})
+
+	t.Run("exception includes the error and the stack", func(t *testing.T) {
+		service.Exception(errors.New("card declined"), "charge failed")
+
+		message := mockLogger.LastPrintedMessage.Message
+		assert.True(t, strings.HasPrefix(message, "charge failed, err card declined\n"), message)
+		assert.Contains(t, message, "TestLoggerService_WithStackTraces")
+	})
+
+	t.Run("other levels have no stack", func(t *testing.T) {
+		service.Warn("slow payment")
+		assert.Equal(t, "slow payment", mockLogger.LastPrintedMessage.Message)
+	})
+
+	t.Run("stack filter applies to captured stacks", func(t *testing.T) {
+		service.WithStackFilter([]string{"testing."})
+		logPaymentFailure(service)
+
+		assert.NotContains(t, mockLogger.LastPrintedMessage.Message, "testing.tRunner")
+	})
+}
+
+// logThroughWrapper logs on behalf of its caller, like a wrapper around the
+// logger, and returns the line of its own logging call
+//
+//go:noinline
+func logThroughWrapper(service *LoggerService, message string) int {
+	_, _, line, _ := runtime.Caller(0)
+	service.Info(message)
+	return line + 1
+}
+
+func TestLoggerService_WithCaller(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = time.Now }()
+
+	var output bytes.Buffer
+	cmdLogger := &CmdLogger{writer: &output}
+	fileName := filepath.Join(t.TempDir(), "caller.log")
+	fileLogger := FileLogger{filename: fileName}.Init().(*FileLogger)
+	channelLogger := (&ChannelLogger{}).Init().(*ChannelLogger)
+	_, ch := channelLogger.Subscribe("caller", func(LogMessage) bool { return true })
+	service := &LoggerService{
+		LogLevel: Info,
+		Loggers:  []Logger{cmdLogger, fileLogger, channelLogger},
+	}
+	service.WithTimestamp().WithCaller(true)
+
+	_, _, line, _ := runtime.Caller(0)
+	service.Info("server started")
+	fileLogger.Close()
+
+	location := fmt.Sprintf("stack_test.go:%d", line+1)
+	assert.Equal(t, "\x1b[0m2024-01-01T10:00:00Z "+location+" server started\x1b[0m\n", output.String())
+	content, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-01T10:00:00Z "+location+" server started\n", string(content))
+	assert.Equal(t, location+" server started", (<-ch).Message)
+
+	t.Run("caller skip points at the wrapper caller", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+		service.WithCaller(true)
+
+		wrapperLine := logThroughWrapper(service, "wrapped")
+		assert.Equal(t, fmt.Sprintf("stack_test.go:%d wrapped", wrapperLine), mockLogger.LastPrintedMessage.Message)
+
+		service.SetCallerSkip(1)
+		_, _, line, _ := runtime.Caller(0)
+		logThroughWrapper(service, "wrapped")
+		assert.Equal(t, fmt.Sprintf("stack_test.go:%d wrapped", line+1), mockLogger.LastPrintedMessage.Message)
+	})
+
+	t.Run("low level and task methods", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+		service.WithCaller(true)
+
+		_, _, line, _ := runtime.Caller(0)
+		service.Log("logged %s", Info, "directly")
+		service.TaskWarn("task warning")
+		assert.Equal(t, []string{
+			fmt.Sprintf("stack_test.go:%d logged directly", line+1),
+			fmt.Sprintf("stack_test.go:%d task warning", line+2),
+		}, mockMessages(mockLogger))
+	})
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// summaryLevels is the order levels are listed in the close summary, info,
+// warn and error are always listed, the others only when they were logged
+var summaryLevels = []string{"info", "success", "notice", "command", "disabled", "warn", "error", "debug", "trace"}
+
+// countMessage records a message logged at the level name
+func (l *LoggerService) countMessage(level string) {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+
+	if l.counts == nil {
+		l.counts = make(map[string]int64)
+	}
+	l.counts[level]++
+}
+
+// Stats returns the number of messages logged per level name, such as "info"
+// or "warn", since the service was created.
+//
+// Example:
+//
+//	service := log.New()
+//	service.Warn("Disk almost full")
+//	fmt.Println(service.Stats()["warn"])
+//	// Output: 1
+func (l *LoggerService) Stats() map[string]int64 {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+
+	stats := make(map[string]int64, len(l.counts))
+	for level, count := range l.counts {
+		stats[level] = count
+	}
+	return stats
+}
+
+// summary renders the per-level counts and the time since the service started
+func (l *LoggerService) summary() string {
+	stats := l.Stats()
+	parts := make([]string, 0, len(summaryLevels))
+	for _, level := range summaryLevels {
+		count := stats[level]
+		if count == 0 && level != "info" && level != "warn" && level != "error" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", count, level))
+	}
+
+	duration := time.Duration(0)
+	if !l.startedAt.IsZero() {
+		duration = nowFunc().Sub(l.startedAt).Round(time.Millisecond)
+	}
+	return fmt.Sprintf("Completed: %s in %s", strings.Join(parts, ", "), duration)
+}
@@ -0,0 +1,255 @@
+//go:build !windows && !plan9
+
+package log
+
+import (
+	"log/syslog"
+	"strings"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+)
+
+// syslogWriter is the part of *syslog.Writer used by the SyslogLogger
+type syslogWriter interface {
+	Err(message string) error
+	Warning(message string) error
+	Info(message string) error
+	Debug(message string) error
+	Close() error
+}
+
+// SyslogLogger Syslog Logger implementation, messages are plain text without
+// icons or colors and the timestamp is left to the syslog daemon
+type SyslogLogger struct {
+	useTimestamp      bool
+	userCorrelationId bool
+	useIcons          bool
+	correlationEnv    string
+	network           string
+	addr              string
+	tag               string
+	enabled           bool
+	writer            syslogWriter
+	redactors         []Redactor
+}
+
+// Init connects to the syslog daemon, an empty network connects to the local
+// daemon. The logger is disabled when the connection fails.
+func (l SyslogLogger) Init() Logger {
+	logger := &SyslogLogger{
+		useTimestamp:      false,
+		userCorrelationId: false,
+		useIcons:          false,
+		network:           l.network,
+		addr:              l.addr,
+		tag:               l.tag,
+	}
+	writer, err := syslog.Dial(l.network, l.addr, syslog.LOG_INFO|syslog.LOG_USER, l.tag)
+	if err != nil {
+		return logger
+	}
+	logger.writer = writer
+	logger.enabled = true
+	return logger
+}
+
+// AddSyslogLogger adds a syslog logger to the LoggerService.
+// The syslog logger forwards messages to the syslog daemon at addr over the
+// network, such as "udp" or "tcp", an empty network uses the local daemon.
+// Levels map to the syslog priorities, Error to LOG_ERR, Warning to LOG_WARNING,
+// Info to LOG_INFO and Debug and Trace to LOG_DEBUG.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddSyslogLogger("udp", "localhost:514", "my-app")
+//	service.Info("Hello from syslog logger!")
+//	// rsyslog: my-app[1234]: Hello from syslog logger!
+func (l *LoggerService) AddSyslogLogger(network, addr, tag string) {
+	l.register(&SyslogLogger{
+		userCorrelationId: l.useCorrelationId,
+		useIcons:          l.useIcons,
+		useTimestamp:      l.UseTimestamp,
+		network:           network,
+		addr:              addr,
+		tag:               tag,
+	})
+}
+
+func (l *SyslogLogger) IsTimestampEnabled() bool {
+	return l.useTimestamp
+}
+
+func (l *SyslogLogger) UseTimestamp(value bool) {
+	l.useTimestamp = value
+}
+
+func (l *SyslogLogger) UseCorrelationId(value bool) {
+	l.userCorrelationId = value
+}
+
+func (l *SyslogLogger) UseIcons(value bool) {
+	l.useIcons = value
+}
+
+// SetCorrelationEnv sets the environment variable the correlation id is read
+// from, CORRELATION_ID when empty
+func (l *SyslogLogger) SetCorrelationEnv(name string) {
+	l.correlationEnv = name
+}
+
+// SetRedactors sets the redactors run on every message before it is written
+func (l *SyslogLogger) SetRedactors(redactors []Redactor) {
+	l.redactors = redactors
+}
+
+// WouldLog reports whether a message at the level would be sent to syslog
+func (l *SyslogLogger) WouldLog(level Level) bool {
+	return l.enabled
+}
+
+// Log Log information message
+func (l *SyslogLogger) Log(format string, level Level, words ...interface{}) {
+	switch level {
+	case 0:
+		l.printMessage(format, "error", correlationIdFromEnv(l.correlationEnv), words...)
+	case 1:
+		l.printMessage(format, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+	case 2:
+		l.printMessage(format, "info", correlationIdFromEnv(l.correlationEnv), words...)
+	case 3:
+		l.printMessage(format, "debug", correlationIdFromEnv(l.correlationEnv), words...)
+	case 4:
+		l.printMessage(format, "trace", correlationIdFromEnv(l.correlationEnv), words...)
+	}
+}
+
+// LogIcon Log information message, the icon is dropped
+func (l *SyslogLogger) LogIcon(icon LoggerIcon, format string, level Level, words ...interface{}) {
+	l.Log(format, level, words...)
+}
+
+// LogHighlight Log information message, the highlight color is dropped
+func (l *SyslogLogger) LogHighlight(format string, level Level, highlightColor strcolor.ColorCode, words ...interface{}) {
+	l.Log(format, level, words...)
+}
+
+// Info log information message
+func (l *SyslogLogger) Info(format string, words ...interface{}) {
+	l.printMessage(format, "info", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Success log message
+func (l *SyslogLogger) Success(format string, words ...interface{}) {
+	l.printMessage(format, "success", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Warn log message
+func (l *SyslogLogger) Warn(format string, words ...interface{}) {
+	l.printMessage(format, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Command log message
+func (l *SyslogLogger) Command(format string, words ...interface{}) {
+	l.printMessage(format, "command", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Disabled log message
+func (l *SyslogLogger) Disabled(format string, words ...interface{}) {
+	l.printMessage(format, "disabled", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Notice log message
+func (l *SyslogLogger) Notice(format string, words ...interface{}) {
+	l.printMessage(format, "notice", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Debug log message
+func (l *SyslogLogger) Debug(format string, words ...interface{}) {
+	l.printMessage(format, "debug", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Trace log message
+func (l *SyslogLogger) Trace(format string, words ...interface{}) {
+	l.printMessage(format, "trace", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Error log message
+func (l *SyslogLogger) Error(format string, words ...interface{}) {
+	l.printMessage(format, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Exception log message
+func (l *SyslogLogger) Exception(err error, format string, words ...interface{}) {
+	if format == "" {
+		format = err.Error()
+	} else {
+		format = format + ", err " + err.Error()
+	}
+	l.printMessage(format, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// LogError log message
+func (l *SyslogLogger) LogError(message error) {
+	if message != nil {
+		l.printMessage(message.Error(), "error", correlationIdFromEnv(l.correlationEnv))
+	}
+}
+
+// Fatal log message
+func (l *SyslogLogger) Fatal(format string, words ...interface{}) {
+	l.printMessage(format, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// FatalError log message
+func (l *SyslogLogger) FatalError(e error, format string, words ...interface{}) {
+	l.Error(format, words...)
+	if e != nil {
+		panic(e)
+	}
+}
+
+// Close closes the connection to the syslog daemon
+func (l *SyslogLogger) Close() error {
+	if !l.enabled {
+		return nil
+	}
+
+	l.enabled = false
+	return l.writer.Close()
+}
+
+// printCorrelated prints a message using a correlation id already resolved by the caller
+func (l *SyslogLogger) printCorrelated(correlationId string, format string, icon LoggerIcon, level string, words ...interface{}) {
+	l.printMessage(format, level, correlationId, words...)
+}
+
+// printMessage sends a plain text message with the syslog priority of the level
+func (l *SyslogLogger) printMessage(format string, level string, correlationId string, words ...interface{}) {
+	if !l.enabled {
+		return
+	}
+
+	message := format
+	if len(words) > 0 {
+		message = formatMessage(format, words...)
+	}
+	message = stripColors(message)
+
+	if l.userCorrelationId && correlationId != "" {
+		message = "[" + correlationId + "] " + message
+	}
+
+	message = redact(l.redactors, message)
+
+	switch strings.ToLower(level) {
+	case "error":
+		l.writer.Err(message)
+	case "warn":
+		l.writer.Warning(message)
+	case "debug", "trace":
+		l.writer.Debug(message)
+	default:
+		l.writer.Info(message)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build !windows && !plan9
+
+package log
+
+import (
+	"errors"
+	"testing"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+	"github.com/stretchr/testify/assert"
+)
+
+type syslogEntry struct {
+	priority string
+	message  string
+}
+
+type fakeSyslogWriter struct {
+	entries []syslogEntry
+	closed  bool
+}
+
+func (w *fakeSyslogWriter) write(priority string, message string) error {
+	w.entries = append(w.entries, syslogEntry{priority: priority, message: message})
+	return nil
+}
+
+func (w *fakeSyslogWriter) Err(message string) error     { return w.write("err", message) }
+func (w *fakeSyslogWriter) Warning(message string) error { return w.write("warning", message) }
+func (w *fakeSyslogWriter) Info(message string) error    { return w.write("info", message) }
+func (w *fakeSyslogWriter) Debug(message string) error   { return w.write("debug", message) }
+func (w *fakeSyslogWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func newTestSyslogLogger() (*SyslogLogger, *fakeSyslogWriter) {
+	writer := &fakeSyslogWriter{}
+	return &SyslogLogger{enabled: true, writer: writer}, writer
+}
+
+func TestSyslogLogger_Priorities(t *testing.T) {
+	tests := []struct {
+		name     string
+		logFunc  func(l *SyslogLogger)
+		expected syslogEntry
+	}{
+		{"error", func(l *SyslogLogger) { l.Error("failed %d", 1) }, syslogEntry{"err", "failed 1"}},
+		{"exception", func(l *SyslogLogger) { l.Exception(errors.New("boom"), "failed") }, syslogEntry{"err", "failed, err boom"}},
+		{"warn", func(l *SyslogLogger) { l.Warn("careful") }, syslogEntry{"warning", "careful"}},
+		{"info", func(l *SyslogLogger) { l.Info("hello") }, syslogEntry{"info", "hello"}},
+		{"success", func(l *SyslogLogger) { l.Success("done") }, syslogEntry{"info", "done"}},
+		{"debug", func(l *SyslogLogger) { l.Debug("details") }, syslogEntry{"debug", "details"}},
+		{"trace", func(l *SyslogLogger) { l.Trace("more details") }, syslogEntry{"debug", "more details"}},
+		{"log level", func(l *SyslogLogger) { l.Log("leveled", Warning) }, syslogEntry{"warning", "leveled"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, writer := newTestSyslogLogger()
+			tt.logFunc(logger)
+
+			assert.Equal(t, []syslogEntry{tt.expected}, writer.entries)
+		})
+	}
+}
+
+func TestSyslogLogger_PlainText(t *testing.T) {
+	logger, writer := newTestSyslogLogger()
+	logger.UseIcons(true)
+
+	logger.LogHighlight("user %s", Info, strcolor.BrightYellow, "alice")
+	logger.LogIcon(IconRocket, "launched", Info)
+
+	assert.Equal(t, "user alice", writer.entries[0].message)
+	assert.Equal(t, "launched", writer.entries[1].message)
+}
+
+func TestSyslogLogger_CorrelationId(t *testing.T) {
+	logger, writer := newTestSyslogLogger()
+	logger.UseCorrelationId(true)
+
+	logger.printCorrelated("req-123", "handled", IconInfo, "info")
+
+	assert.Equal(t, "[req-123] handled", writer.entries[0].message)
+}
+
+func TestSyslogLogger_Close(t *testing.T) {
+	logger, writer := newTestSyslogLogger()
+
+	assert.NoError(t, logger.Close())
+	assert.True(t, writer.closed)
+
+	logger.Info("after close")
+	assert.Empty(t, writer.entries)
+}
@@ -0,0 +1,389 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+)
+
+const (
+	// DefaultWebhookBatchSize is the number of messages sent in a single POST
+	DefaultWebhookBatchSize = 100
+	// DefaultWebhookFlushInterval is how often a partial batch is sent
+	DefaultWebhookFlushInterval = 5 * time.Second
+	// webhookRetries is how many times a failed POST is retried before the batch is dropped
+	webhookRetries = 2
+	// webhookQueueSize is how many full batches can wait to be sent before new ones are dropped
+	webhookQueueSize = 16
+)
+
+// WebhookLogger HTTP Logger implementation, messages are batched and POSTed
+// as a JSON array of LogMessage to the url in the background
+type WebhookLogger struct {
+	useTimestamp      bool
+	userCorrelationId bool
+	useIcons          bool
+	correlationEnv    string
+	schemaVersion     string
+	url               string
+	redactors         []Redactor
+	client            *http.Client
+	batchSize         int
+	retryDelay        time.Duration
+	batch             []LogMessage
+	queue             chan []LogMessage
+	ticker            *time.Ticker
+	stop              chan struct{}
+	done              chan struct{}
+	closed            bool
+	batchMutex        sync.Mutex
+}
+
+func (l *WebhookLogger) Init() Logger {
+	logger := &WebhookLogger{
+		useTimestamp:      false,
+		userCorrelationId: false,
+		useIcons:          false,
+		url:               l.url,
+		client:            &http.Client{Timeout: 10 * time.Second},
+		batchSize:         DefaultWebhookBatchSize,
+		retryDelay:        500 * time.Millisecond,
+		batch:             make([]LogMessage, 0),
+		queue:             make(chan []LogMessage, webhookQueueSize),
+		ticker:            time.NewTicker(DefaultWebhookFlushInterval),
+		stop:              make(chan struct{}),
+		done:              make(chan struct{}),
+	}
+	go logger.run()
+	return logger
+}
+
+// AddWebhookLogger adds a webhook logger to the LoggerService.
+// The webhook logger batches messages and POSTs them as a JSON array of
+// LogMessage to the url, a batch is sent when it is full or every flush interval.
+// Failed POSTs are retried a couple of times and then dropped so logging never blocks.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddWebhookLogger("https://collector.example.com/logs")
+//	defer service.Close()
+//	service.Info("Hello from webhook logger!")
+//	// Body: [{"level":"info","message":"Hello from webhook logger!",...}]
+func (l *LoggerService) AddWebhookLogger(url string) {
+	l.register(&WebhookLogger{
+		userCorrelationId: l.useCorrelationId,
+		useIcons:          l.useIcons,
+		useTimestamp:      l.UseTimestamp,
+		url:               url,
+	})
+}
+
+// registrationKey lets loggers writing to different urls be registered together
+func (l *WebhookLogger) registrationKey() string {
+	return l.url
+}
+
+func (l *WebhookLogger) IsTimestampEnabled() bool {
+	return l.useTimestamp
+}
+
+func (l *WebhookLogger) UseTimestamp(value bool) {
+	l.useTimestamp = value
+}
+
+func (l *WebhookLogger) UseCorrelationId(value bool) {
+	l.userCorrelationId = value
+}
+
+func (l *WebhookLogger) UseIcons(value bool) {
+	l.useIcons = value
+}
+
+// SetCorrelationEnv sets the environment variable the correlation id is read
+// from, CORRELATION_ID when empty
+func (l *WebhookLogger) SetCorrelationEnv(name string) {
+	l.correlationEnv = name
+}
+
+// SetRedactors sets the redactors run on every message before it is written
+func (l *WebhookLogger) SetRedactors(redactors []Redactor) {
+	l.redactors = redactors
+}
+
+// SetSchemaVersion stamps the version into every posted LogMessage, an empty
+// version leaves the field out
+func (l *WebhookLogger) SetSchemaVersion(version string) {
+	l.schemaVersion = version
+}
+
+// SetBatchSize sets the number of messages sent in a single POST
+func (l *WebhookLogger) SetBatchSize(size int) {
+	if size <= 0 {
+		size = DefaultWebhookBatchSize
+	}
+
+	l.batchMutex.Lock()
+	defer l.batchMutex.Unlock()
+
+	l.batchSize = size
+}
+
+// SetFlushInterval sets how often a partial batch is sent
+func (l *WebhookLogger) SetFlushInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultWebhookFlushInterval
+	}
+
+	l.ticker.Reset(interval)
+}
+
+// Log Log information message
+func (l *WebhookLogger) Log(format string, level Level, words ...interface{}) {
+	switch level {
+	case 0:
+		l.printMessage(format, "", "error", correlationIdFromEnv(l.correlationEnv), words...)
+	case 1:
+		l.printMessage(format, "", "warn", correlationIdFromEnv(l.correlationEnv), words...)
+	case 2:
+		l.printMessage(format, "", "info", correlationIdFromEnv(l.correlationEnv), words...)
+	case 3:
+		l.printMessage(format, "", "debug", correlationIdFromEnv(l.correlationEnv), words...)
+	case 4:
+		l.printMessage(format, "", "trace", correlationIdFromEnv(l.correlationEnv), words...)
+	}
+}
+
+// LogIcon Log information message
+func (l *WebhookLogger) LogIcon(icon LoggerIcon, format string, level Level, words ...interface{}) {
+	switch level {
+	case 0:
+		l.printMessage(format, icon, "error", correlationIdFromEnv(l.correlationEnv), words...)
+	case 1:
+		l.printMessage(format, icon, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+	case 2:
+		l.printMessage(format, icon, "info", correlationIdFromEnv(l.correlationEnv), words...)
+	case 3:
+		l.printMessage(format, icon, "debug", correlationIdFromEnv(l.correlationEnv), words...)
+	case 4:
+		l.printMessage(format, icon, "trace", correlationIdFromEnv(l.correlationEnv), words...)
+	}
+}
+
+// LogHighlight Log information message, the highlight color is dropped as
+// the message is sent as JSON
+func (l *WebhookLogger) LogHighlight(format string, level Level, highlightColor strcolor.ColorCode, words ...interface{}) {
+	l.Log(format, level, words...)
+}
+
+// Info log information message
+func (l *WebhookLogger) Info(format string, words ...interface{}) {
+	l.printMessage(format, IconInfo, "info", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Success log message
+func (l *WebhookLogger) Success(format string, words ...interface{}) {
+	l.printMessage(format, IconThumbsUp, "success", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Warn log message
+func (l *WebhookLogger) Warn(format string, words ...interface{}) {
+	l.printMessage(format, IconWarning, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Command log message
+func (l *WebhookLogger) Command(format string, words ...interface{}) {
+	l.printMessage(format, IconWrench, "command", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Disabled log message
+func (l *WebhookLogger) Disabled(format string, words ...interface{}) {
+	l.printMessage(format, IconBlackSquare, "disabled", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Notice log message
+func (l *WebhookLogger) Notice(format string, words ...interface{}) {
+	l.printMessage(format, IconFlag, "notice", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Debug log message
+func (l *WebhookLogger) Debug(format string, words ...interface{}) {
+	l.printMessage(format, IconFire, "debug", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Trace log message
+func (l *WebhookLogger) Trace(format string, words ...interface{}) {
+	l.printMessage(format, IconBulb, "trace", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Error log message
+func (l *WebhookLogger) Error(format string, words ...interface{}) {
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Exception log message
+func (l *WebhookLogger) Exception(err error, format string, words ...interface{}) {
+	if format == "" {
+		format = err.Error()
+	} else {
+		format = format + ", err " + err.Error()
+	}
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// LogError log message
+func (l *WebhookLogger) LogError(message error) {
+	if message != nil {
+		l.printMessage(message.Error(), IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv))
+	}
+}
+
+// Fatal log message
+func (l *WebhookLogger) Fatal(format string, words ...interface{}) {
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// FatalError log message
+func (l *WebhookLogger) FatalError(e error, format string, words ...interface{}) {
+	l.Error(format, words...)
+	l.Flush()
+	if e != nil {
+		panic(e)
+	}
+}
+
+// Close sends the final batch and stops the background sender, messages
+// logged after Close are dropped
+func (l *WebhookLogger) Close() {
+	l.batchMutex.Lock()
+	if l.closed {
+		l.batchMutex.Unlock()
+		return
+	}
+	l.closed = true
+	l.batchMutex.Unlock()
+
+	close(l.stop)
+	<-l.done
+}
+
+// Flush sends the queued batches and the partial batch before returning,
+// failed requests are retried and then dropped as they are in the background
+func (l *WebhookLogger) Flush() error {
+	for {
+		select {
+		case batch := <-l.queue:
+			l.post(batch)
+		default:
+			l.post(l.takeBatch())
+			return nil
+		}
+	}
+}
+
+// printCorrelated prints a message using a correlation id already resolved by the caller
+func (l *WebhookLogger) printCorrelated(correlationId string, format string, icon LoggerIcon, level string, words ...interface{}) {
+	l.printMessage(format, icon, level, correlationId, words...)
+}
+
+// printMessage adds a message without fields to the current batch
+func (l *WebhookLogger) printMessage(format string, icon LoggerIcon, level string, correlationId string, words ...interface{}) {
+	l.printStructured(correlationId, messageMeta{}, format, icon, level, words...)
+}
+
+// printStructured adds a message to the current batch, queuing the batch to be
+// sent once it is full
+func (l *WebhookLogger) printStructured(correlationId string, meta messageMeta, format string, icon LoggerIcon, level string, words ...interface{}) {
+	msg := newLogMessage(format, icon, level, l.useIcons, "", words...)
+	msg.SchemaVersion = l.schemaVersion
+	meta.apply(&msg)
+	if l.userCorrelationId && correlationId != "" {
+		msg.Message = "[" + correlationId + "] " + msg.Message
+	}
+	redactMessage(l.redactors, &msg)
+
+	l.batchMutex.Lock()
+	defer l.batchMutex.Unlock()
+
+	if l.closed {
+		return
+	}
+
+	l.batch = append(l.batch, msg)
+	if len(l.batch) >= l.batchSize {
+		select {
+		case l.queue <- l.batch:
+		default:
+			// Too many batches are waiting to be sent, drop this one
+		}
+		l.batch = make([]LogMessage, 0)
+	}
+}
+
+// takeBatch returns the current partial batch and starts a new one
+func (l *WebhookLogger) takeBatch() []LogMessage {
+	l.batchMutex.Lock()
+	defer l.batchMutex.Unlock()
+
+	batch := l.batch
+	l.batch = make([]LogMessage, 0)
+	return batch
+}
+
+// run sends the queued batches and the partial batch on every tick until the
+// logger is closed, then sends whatever is left
+func (l *WebhookLogger) run() {
+	defer close(l.done)
+	defer l.ticker.Stop()
+
+	for {
+		select {
+		case batch := <-l.queue:
+			l.post(batch)
+		case <-l.ticker.C:
+			l.post(l.takeBatch())
+		case <-l.stop:
+			for {
+				select {
+				case batch := <-l.queue:
+					l.post(batch)
+				default:
+					l.post(l.takeBatch())
+					return
+				}
+			}
+		}
+	}
+}
+
+// post sends the batch, retrying a failed request a couple of times before
+// the batch is dropped
+func (l *WebhookLogger) post(batch []LogMessage) {
+	if len(batch) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return
+	}
+
+	for attempt := 0; attempt <= webhookRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(l.retryDelay * time.Duration(attempt))
+		}
+
+		response, err := l.client.Post(l.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			continue
+		}
+		response.Body.Close()
+		if response.StatusCode >= 200 && response.StatusCode < 300 {
+			return
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookCollector struct {
+	mutex    sync.Mutex
+	batches  [][]LogMessage
+	requests int
+	failures int
+}
+
+func (c *webhookCollector) handler(w http.ResponseWriter, r *http.Request) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.requests++
+	if c.failures > 0 {
+		c.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var batch []LogMessage
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.batches = append(c.batches, batch)
+}
+
+func (c *webhookCollector) snapshot() ([][]LogMessage, int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([][]LogMessage{}, c.batches...), c.requests
+}
+
+func newTestWebhookLogger(t *testing.T, collector *webhookCollector) *WebhookLogger {
+	server := httptest.NewServer(http.HandlerFunc(collector.handler))
+	t.Cleanup(server.Close)
+
+	logger := (&WebhookLogger{url: server.URL}).Init().(*WebhookLogger)
+	logger.retryDelay = time.Millisecond
+	return logger
+}
+
+func TestWebhookLogger_BatchSize(t *testing.T) {
+	collector := &webhookCollector{}
+	logger := newTestWebhookLogger(t, collector)
+	logger.SetBatchSize(2)
+
+	logger.Info("first")
+	logger.Warn("second %d", 2)
+	logger.Error("third")
+
+	assert.Eventually(t, func() bool {
+		batches, _ := collector.snapshot()
+		return len(batches) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// Close sends the final partial batch
+	logger.Close()
+	batches, _ := collector.snapshot()
+	assert.Len(t, batches, 2)
+	assert.Equal(t, "first", batches[0][0].Message)
+	assert.Equal(t, "info", batches[0][0].Level)
+	assert.Equal(t, "second 2", batches[0][1].Message)
+	assert.Equal(t, "warn", batches[0][1].Level)
+	assert.Equal(t, "third", batches[1][0].Message)
+
+	logger.Info("after close")
+	logger.Close()
+	batches, _ = collector.snapshot()
+	assert.Len(t, batches, 2)
+}
+
+func TestWebhookLogger_FlushInterval(t *testing.T) {
+	collector := &webhookCollector{}
+	logger := newTestWebhookLogger(t, collector)
+	defer logger.Close()
+	logger.SetFlushInterval(10 * time.Millisecond)
+
+	logger.Info("partial batch")
+
+	assert.Eventually(t, func() bool {
+		batches, _ := collector.snapshot()
+		return len(batches) == 1 && batches[0][0].Message == "partial batch"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWebhookLogger_Retries(t *testing.T) {
+	t.Run("succeeds after a failure", func(t *testing.T) {
+		collector := &webhookCollector{failures: 1}
+		logger := newTestWebhookLogger(t, collector)
+
+		logger.Info("retried")
+		logger.Close()
+
+		batches, requests := collector.snapshot()
+		assert.Equal(t, 2, requests)
+		assert.Len(t, batches, 1)
+	})
+
+	t.Run("drops the batch after the retries", func(t *testing.T) {
+		collector := &webhookCollector{failures: 10}
+		logger := newTestWebhookLogger(t, collector)
+
+		logger.Info("dropped")
+		logger.Close()
+
+		batches, requests := collector.snapshot()
+		assert.Equal(t, webhookRetries+1, requests)
+		assert.Empty(t, batches)
+	})
+}
+
+func TestWebhookLogger_CorrelationId(t *testing.T) {
+	collector := &webhookCollector{}
+	logger := newTestWebhookLogger(t, collector)
+	logger.UseCorrelationId(true)
+
+	logger.printCorrelated("req-123", "handled", IconInfo, "info")
+	logger.Close()
+
+	batches, _ := collector.snapshot()
+	assert.Equal(t, "[req-123] handled", batches[0][0].Message)
+}
+
+func TestWebhookLogger_Flush(t *testing.T) {
+	collector := &webhookCollector{}
+	logger := newTestWebhookLogger(t, collector)
+	defer logger.Close()
+	logger.SetFlushInterval(time.Hour)
+
+	logger.Error("crashing")
+	batches, _ := collector.snapshot()
+	assert.Empty(t, batches)
+
+	assert.NoError(t, logger.Flush())
+	batches, _ = collector.snapshot()
+	assert.Len(t, batches, 1)
+	assert.Equal(t, "crashing", batches[0][0].Message)
+}
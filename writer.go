@@ -0,0 +1,81 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// levelWriter is an io.Writer that logs every line written to it at a fixed level
+type levelWriter struct {
+	service *LoggerService
+	level   Level
+	buffer  []byte
+	mutex   sync.Mutex
+}
+
+// flushWriter flushes writers that buffer their output, such as a bufio.Writer,
+// other writers write straight through and are left as they are
+func flushWriter(w io.Writer) error {
+	flusher, ok := w.(Flusher)
+	if !ok {
+		return nil
+	}
+	return flusher.Flush()
+}
+
+// Writer returns an io.Writer that splits the bytes written to it into lines
+// and logs each line at the given level through all registered loggers.
+// Partial writes without a trailing newline are buffered until the newline arrives.
+// This allows piping the standard library logger or third-party output through the service.
+//
+// Example:
+//
+//	service := log.New()
+//	stdlog.SetOutput(service.Writer(log.Info))
+//	stdlog.SetFlags(0)
+//	stdlog.Print("hello from the standard library")
+//	// Output: hello from the standard library
+func (l *LoggerService) Writer(level Level) io.Writer {
+	return &levelWriter{
+		service: l,
+		level:   level,
+	}
+}
+
+// Write buffers p and logs every complete line, it never fails
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buffer = append(w.buffer, p...)
+	for {
+		index := bytes.IndexByte(w.buffer, '\n')
+		if index < 0 {
+			break
+		}
+
+		line := strings.TrimSuffix(string(w.buffer[:index]), "\r")
+		w.buffer = w.buffer[index+1:]
+		w.log(line)
+	}
+
+	return len(p), nil
+}
+
+// log sends a single line to the service using the method for the writer level
+func (w *levelWriter) log(line string) {
+	switch w.level {
+	case Error:
+		w.service.Error("%s", line)
+	case Warning:
+		w.service.Warn("%s", line)
+	case Info:
+		w.service.Info("%s", line)
+	case Debug:
+		w.service.Debug("%s", line)
+	case Trace:
+		w.service.Trace("%s", line)
+	}
+}
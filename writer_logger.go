@@ -0,0 +1,258 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+)
+
+// WriterLogger io.Writer Logger implementation, every message is rendered by
+// the logger encoder and written with a single Write, so several writer loggers
+// with different encoders can share or split destinations
+type WriterLogger struct {
+	useTimestamp      bool
+	userCorrelationId bool
+	useIcons          bool
+	correlationEnv    string
+	schemaVersion     string
+	writer            io.Writer
+	encoder           Encoder
+	redactors         []Redactor
+	writerMutex       sync.Mutex
+}
+
+func (l *WriterLogger) Init() Logger {
+	writer := l.writer
+	if writer == nil {
+		writer = os.Stdout
+	}
+	encoder := l.encoder
+	if encoder == nil {
+		encoder = TextEncoder{}
+	}
+
+	return &WriterLogger{
+		useTimestamp:      false,
+		userCorrelationId: false,
+		useIcons:          false,
+		writer:            writer,
+		encoder:           encoder,
+	}
+}
+
+// AddWriterLogger adds a logger writing to the writer with the chosen encoder
+// to the LoggerService. Several writer loggers can be added, each with its own
+// encoder, a nil writer uses stdout and a nil encoder uses the TextEncoder.
+//
+// Example:
+//
+//	service := log.New()
+//	service.AddWriterLogger(os.Stdout, log.TextEncoder{})
+//	service.AddWriterLogger(file, log.JSONEncoder{})
+//	service.Info("Hello from writer loggers!")
+//	// Output: Hello from writer loggers!
+func (l *LoggerService) AddWriterLogger(writer io.Writer, encoder Encoder) {
+	l.AddLogger((&WriterLogger{writer: writer, encoder: encoder}).Init())
+}
+
+func (l *WriterLogger) IsTimestampEnabled() bool {
+	return l.useTimestamp
+}
+
+func (l *WriterLogger) UseTimestamp(value bool) {
+	l.useTimestamp = value
+}
+
+func (l *WriterLogger) UseCorrelationId(value bool) {
+	l.userCorrelationId = value
+}
+
+func (l *WriterLogger) UseIcons(value bool) {
+	l.useIcons = value
+}
+
+// SetCorrelationEnv sets the environment variable the correlation id is read
+// from, CORRELATION_ID when empty
+func (l *WriterLogger) SetCorrelationEnv(name string) {
+	l.correlationEnv = name
+}
+
+// SetRedactors sets the redactors run on every message before it is written
+func (l *WriterLogger) SetRedactors(redactors []Redactor) {
+	l.redactors = redactors
+}
+
+// SetSchemaVersion stamps the version into every written LogMessage, an empty
+// version leaves the field out
+func (l *WriterLogger) SetSchemaVersion(version string) {
+	l.schemaVersion = version
+}
+
+// Log Log information message
+func (l *WriterLogger) Log(format string, level Level, words ...interface{}) {
+	switch level {
+	case 0:
+		l.printMessage(format, "", "error", correlationIdFromEnv(l.correlationEnv), words...)
+	case 1:
+		l.printMessage(format, "", "warn", correlationIdFromEnv(l.correlationEnv), words...)
+	case 2:
+		l.printMessage(format, "", "info", correlationIdFromEnv(l.correlationEnv), words...)
+	case 3:
+		l.printMessage(format, "", "debug", correlationIdFromEnv(l.correlationEnv), words...)
+	case 4:
+		l.printMessage(format, "", "trace", correlationIdFromEnv(l.correlationEnv), words...)
+	}
+}
+
+// LogIcon Log information message
+func (l *WriterLogger) LogIcon(icon LoggerIcon, format string, level Level, words ...interface{}) {
+	switch level {
+	case 0:
+		l.printMessage(format, icon, "error", correlationIdFromEnv(l.correlationEnv), words...)
+	case 1:
+		l.printMessage(format, icon, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+	case 2:
+		l.printMessage(format, icon, "info", correlationIdFromEnv(l.correlationEnv), words...)
+	case 3:
+		l.printMessage(format, icon, "debug", correlationIdFromEnv(l.correlationEnv), words...)
+	case 4:
+		l.printMessage(format, icon, "trace", correlationIdFromEnv(l.correlationEnv), words...)
+	}
+}
+
+// LogHighlight Log information message, the highlight color is dropped as
+// the encoders write plain text
+func (l *WriterLogger) LogHighlight(format string, level Level, highlightColor strcolor.ColorCode, words ...interface{}) {
+	l.Log(format, level, words...)
+}
+
+// Info log information message
+func (l *WriterLogger) Info(format string, words ...interface{}) {
+	l.printMessage(format, IconInfo, "info", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Success log message
+func (l *WriterLogger) Success(format string, words ...interface{}) {
+	l.printMessage(format, IconThumbsUp, "success", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Warn log message
+func (l *WriterLogger) Warn(format string, words ...interface{}) {
+	l.printMessage(format, IconWarning, "warn", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Command log message
+func (l *WriterLogger) Command(format string, words ...interface{}) {
+	l.printMessage(format, IconWrench, "command", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Disabled log message
+func (l *WriterLogger) Disabled(format string, words ...interface{}) {
+	l.printMessage(format, IconBlackSquare, "disabled", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Notice log message
+func (l *WriterLogger) Notice(format string, words ...interface{}) {
+	l.printMessage(format, IconFlag, "notice", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Debug log message
+func (l *WriterLogger) Debug(format string, words ...interface{}) {
+	l.printMessage(format, IconFire, "debug", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Trace log message
+func (l *WriterLogger) Trace(format string, words ...interface{}) {
+	l.printMessage(format, IconBulb, "trace", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Error log message
+func (l *WriterLogger) Error(format string, words ...interface{}) {
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// Exception log message
+func (l *WriterLogger) Exception(err error, format string, words ...interface{}) {
+	if format == "" {
+		format = err.Error()
+	} else {
+		format = format + ", err " + err.Error()
+	}
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// LogError log message
+func (l *WriterLogger) LogError(message error) {
+	if message != nil {
+		l.printMessage(message.Error(), IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv))
+	}
+}
+
+// Fatal log message
+func (l *WriterLogger) Fatal(format string, words ...interface{}) {
+	l.printMessage(format, IconRevolvingLight, "error", correlationIdFromEnv(l.correlationEnv), words...)
+}
+
+// FatalError log message
+func (l *WriterLogger) FatalError(e error, format string, words ...interface{}) {
+	l.Error(format, words...)
+	l.Flush()
+	if e != nil {
+		panic(e)
+	}
+}
+
+// SetWriter sets the writer the messages are written to
+func (l *WriterLogger) SetWriter(w io.Writer) {
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	l.writer = w
+}
+
+// Flush flushes the writer when it buffers its output
+func (l *WriterLogger) Flush() error {
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	return flushWriter(l.writer)
+}
+
+// printCorrelated prints a message using a correlation id already resolved by the caller
+func (l *WriterLogger) printCorrelated(correlationId string, format string, icon LoggerIcon, level string, words ...interface{}) {
+	l.printMessage(format, icon, level, correlationId, words...)
+}
+
+// printMessage writes a message without fields
+func (l *WriterLogger) printMessage(format string, icon LoggerIcon, level string, correlationId string, words ...interface{}) {
+	l.printStructured(correlationId, messageMeta{}, format, icon, level, words...)
+}
+
+// printStructured encodes the complete record with the logger encoder and then
+// writes it with a single Write under the writer mutex
+func (l *WriterLogger) printStructured(correlationId string, meta messageMeta, format string, icon LoggerIcon, level string, words ...interface{}) {
+	msg := newLogMessage(format, icon, level, l.useIcons, "", words...)
+	msg.SchemaVersion = l.schemaVersion
+	meta.apply(&msg)
+	if !l.useTimestamp {
+		msg.Timestamp = time.Time{}
+	}
+	if l.userCorrelationId && correlationId != "" {
+		msg.Message = "[" + correlationId + "] " + msg.Message
+	}
+	redactMessage(l.redactors, &msg)
+
+	record, err := l.encoder.Encode(msg)
+	if err != nil {
+		record = []byte(fmt.Sprintf("failed to encode log message: %v\n", err))
+	}
+
+	l.writerMutex.Lock()
+	defer l.writerMutex.Unlock()
+
+	l.writer.Write(record)
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriterLogger_Encoders(t *testing.T) {
+	var jsonOutput, textOutput bytes.Buffer
+	service := &LoggerService{LogLevel: Info}
+	service.AddWriterLogger(&jsonOutput, JSONEncoder{})
+	service.AddWriterLogger(&textOutput, TextEncoder{})
+
+	service.WithField("route", "/login").Info("request handled")
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(jsonOutput.Bytes(), &record))
+	assert.Equal(t, "info", record["level"])
+	assert.Equal(t, "request handled", record["message"])
+	assert.Equal(t, map[string]any{"route": "/login"}, record["fields"])
+	assert.NotContains(t, record, "timestamp")
+
+	assert.Equal(t, "request handled route=/login\n", textOutput.String())
+}
+
+func TestLogfmtEncoder_Encode(t *testing.T) {
+	timestamp := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		msg      LogMessage
+		expected string
+	}{
+		{
+			name:     "message only",
+			msg:      LogMessage{Level: "info", Message: "started"},
+			expected: "level=info msg=started\n",
+		},
+		{
+			name: "every field",
+			msg: LogMessage{
+				Level:     "error",
+				Message:   "request failed",
+				Timestamp: timestamp,
+				Source:    "api",
+				Error:     errors.New("timeout").Error(),
+				Fields:    map[string]any{"user_id": 42, "route": "/a b"},
+			},
+			expected: "time=2024-03-20T10:00:00Z level=error msg=\"request failed\" logger=api error=timeout route=\"/a b\" user_id=42\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := LogfmtEncoder{}.Encode(tt.msg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(record))
+		})
+	}
+}
+
+func TestWriterLogger_Timestamp(t *testing.T) {
+	originalNow := nowFunc
+	defer func() { nowFunc = originalNow }()
+	nowFunc = func() time.Time { return time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC) }
+
+	var output bytes.Buffer
+	service := &LoggerService{LogLevel: Info, UseTimestamp: true}
+	service.AddWriterLogger(&output, LogfmtEncoder{})
+
+	service.Warn("disk at %d%%", 90)
+
+	assert.True(t, strings.HasPrefix(output.String(), "time=2024-03-20T10:00:00Z level=warn "))
+}
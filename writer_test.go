@@ -0,0 +1,74 @@
+package log
+
+import (
+	"fmt"
+	stdlog "log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerService_Writer(t *testing.T) {
+	t.Run("logs each line at the writer level", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+
+		w := service.Writer(Warning)
+		n, err := w.Write([]byte("first line\nsecond 100% line\r\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 29, n)
+		assert.Equal(t, 2, len(mockLogger.PrintedMessages))
+		assert.Equal(t, "first line", mockLogger.PrintedMessages[0].Message)
+		assert.Equal(t, "warn", mockLogger.PrintedMessages[0].Level)
+		assert.Equal(t, "second 100% line", mockLogger.PrintedMessages[1].Message)
+	})
+
+	t.Run("buffers partial writes until a newline", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+
+		w := service.Writer(Info)
+		fmt.Fprint(w, "partial ")
+		assert.Empty(t, mockLogger.PrintedMessages)
+
+		fmt.Fprint(w, "message\nnext")
+		assert.Equal(t, 1, len(mockLogger.PrintedMessages))
+		assert.Equal(t, "partial message", mockLogger.LastPrintedMessage.Message)
+
+		fmt.Fprint(w, "\n")
+		assert.Equal(t, 2, len(mockLogger.PrintedMessages))
+		assert.Equal(t, "next", mockLogger.LastPrintedMessage.Message)
+	})
+
+	t.Run("respects the service log level", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+
+		fmt.Fprintln(service.Writer(Debug), "hidden")
+		assert.Empty(t, mockLogger.PrintedMessages)
+	})
+
+	t.Run("captures the standard library logger", func(t *testing.T) {
+		mockLogger := &MockLogger{}
+		service := &LoggerService{
+			LogLevel: Info,
+			Loggers:  []Logger{mockLogger},
+		}
+
+		logger := stdlog.New(service.Writer(Error), "", 0)
+		logger.Printf("library failed: %s", "timeout")
+
+		assert.Equal(t, "library failed: timeout", mockLogger.LastPrintedMessage.Message)
+		assert.Equal(t, "error", mockLogger.LastPrintedMessage.Level)
+	})
+}